@@ -1,10 +1,14 @@
 package app
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 //go:generate mockgen -source app.go -destination ../testpkg/mock_app/mock_stoper.go
@@ -14,18 +18,56 @@ type Stoper interface {
 	GetStopChan() <-chan struct{}
 }
 
+// AppOption application options
+//
+//go:generate gogen option -n AppOption -f App -o option.app.go
+func walleApplication() interface{} {
+	return map[string]interface{}{
+		// StopTimeout 关闭流程(stop,finish)总超时时间. 0表示不限制
+		"StopTimeout": time.Duration(time.Second * 30),
+		// ServiceStopTimeout 单个服务stop/finish超时时间. 0表示不限制. Stop超时的服务不再执行Finish
+		"ServiceStopTimeout": time.Duration(time.Second * 10),
+		// ServiceStopTimeouts 指定服务(Service.Name())stop/finish超时时间,优先于ServiceStopTimeout
+		"ServiceStopTimeouts": map[string]time.Duration(nil),
+		// ForceExitTimeout 开始停止之后,超过此时间强制退出进程. 0表示不强制退出
+		"ForceExitTimeout": time.Duration(time.Second * 60),
+		// ForceExit 强制退出进程
+		"ForceExit": func() {
+			os.Exit(1)
+		},
+		// ReloadSignals 重新加载信号,通知所有实现 Reloader 接口的服务
+		"ReloadSignals": []os.Signal{syscall.SIGHUP},
+		// ReloadTimeout 重新加载超时时间. 0表示不限制
+		"ReloadTimeout": time.Duration(time.Second * 30),
+		// Observers 服务生命周期观察者
+		"Observers": []LifecycleObserver{StdLogObserver},
+	}
+}
+
 // Application 接口.是service容器.
 // 主要负责调度 service.(init,config,start,stop,finish)
 type Application struct {
 	svc  Service
+	opts *AppOptions
 	stop chan struct{}
 	sign <-chan os.Signal
+	// root service inited
+	inited atomic.Bool
+	// all service started
+	started atomic.Bool
+	// shutdown
+	mux            sync.Mutex
+	exited         bool
+	forceExit      *time.Timer
+	shutdownCtx    context.Context
+	shutdownCancel func()
 }
 
 // CreateApp 新建应用
-func CreateApp(svc Service) *Application {
+func CreateApp(svc Service, opts ...AppOption) *Application {
 	return &Application{
 		svc:  svc,
+		opts: NewAppOptions(opts...),
 		stop: make(chan struct{}),
 	}
 }
@@ -34,21 +76,30 @@ func CreateApp(svc Service) *Application {
 func (app *Application) Run() (err error) {
 	svr := app.svc
 	app.sign = StopSignal()
+	defer stopNotify(app.sign)
+	// Run返回时关闭,通知信号监听协程退出
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
 		select {
 		case <-app.sign:
 			app.Stop()
 		case <-app.stop:
-			return
+		case <-done:
 		}
 	}()
+	defer app.exit()
 	// 服务初始化
 	err = svr.Init(app)
 	if err != nil {
 		return
 	}
+	app.inited.Store(true)
 	// 服务卸载清理
-	defer svr.Finish()
+	defer func() {
+		app.inited.Store(false)
+		finishService(app.shutdownContext(), svr)
+	}()
 	// 已经异步停止服务
 	if app.IsStop() {
 		return
@@ -59,7 +110,15 @@ func (app *Application) Run() (err error) {
 		return
 	}
 	// 服务停止
-	defer svr.Stop()
+	defer func() {
+		app.started.Store(false)
+		stopService(app.shutdownContext(), svr)
+	}()
+	app.started.Store(true)
+	// 启动完成之后响应重新加载信号
+	reload := ReloadSignal(app.opts.ReloadSignals...)
+	defer stopNotify(reload)
+	go app.watchReload(reload, done)
 	// 已经异步停止服务
 	if app.IsStop() {
 		return
@@ -80,6 +139,7 @@ func (app *Application) Stop() {
 	default:
 		close(app.stop)
 	}
+	app.startForceExit()
 }
 
 func (app *Application) IsStop() bool {
@@ -98,13 +158,94 @@ func (app *Application) GetStopChan() <-chan struct{} {
 	return app.stop
 }
 
+// shutdownContext 关闭流程使用的context,超过StopTimeout之后,所有服务的stop/finish不再等待.
+func (app *Application) shutdownContext() context.Context {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+	if app.shutdownCtx == nil {
+		ctx := shutdownStateContext(context.WithValue(context.Background(), appOptionsKey{}, app.opts))
+		if app.opts.StopTimeout > 0 {
+			app.shutdownCtx, app.shutdownCancel = context.WithTimeout(ctx, app.opts.StopTimeout)
+		} else {
+			app.shutdownCtx, app.shutdownCancel = context.WithCancel(ctx)
+		}
+	}
+	app.startForceExitLocked()
+	return app.shutdownCtx
+}
+
+// startForceExit 开始停止,超时强制退出进程
+func (app *Application) startForceExit() {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+	app.startForceExitLocked()
+}
+
+func (app *Application) startForceExitLocked() {
+	if app.exited || app.forceExit != nil || app.opts.ForceExitTimeout <= 0 {
+		return
+	}
+	timeout := app.opts.ForceExitTimeout
+	app.forceExit = time.AfterFunc(timeout, func() {
+		log.Printf("application stop timeout(%s), force exit\n", timeout)
+		app.opts.ForceExit()
+	})
+}
+
+// exit 应用退出,清理关闭流程
+func (app *Application) exit() {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+	app.exited = true
+	if app.forceExit != nil {
+		app.forceExit.Stop()
+	}
+	if app.shutdownCancel != nil {
+		app.shutdownCancel()
+	}
+}
+
 // StopSignal 停止信号函数
 var StopSignal = func() <-chan os.Signal {
+	return notifySignal(syscall.SIGINT, syscall.SIGKILL, syscall.SIGTERM)
+}
+
+// ReloadSignal 重新加载信号函数
+var ReloadSignal = func(sig ...os.Signal) <-chan os.Signal {
+	return notifySignal(sig...)
+}
+
+// 已经注册的信号通知,Run返回时取消
+var (
+	signalMux   sync.Mutex
+	signalChans = make(map[<-chan os.Signal]chan os.Signal)
+)
+
+// notifySignal 注册信号通知,使用 stopNotify 取消
+func notifySignal(sig ...os.Signal) <-chan os.Signal {
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGKILL, syscall.SIGTERM)
+	if len(sig) == 0 {
+		return c
+	}
+	signal.Notify(c, sig...)
+	signalMux.Lock()
+	signalChans[c] = c
+	signalMux.Unlock()
 	return c
 }
 
+// stopNotify 取消信号通知. 自定义信号函数返回的chan不处理.
+// signal.Stop 需要等待信号处理协程空闲,在后台执行,不阻塞Run返回.
+func stopNotify(c <-chan os.Signal) {
+	signalMux.Lock()
+	sc, ok := signalChans[c]
+	delete(signalChans, c)
+	signalMux.Unlock()
+	if ok {
+		go signal.Stop(sc)
+	}
+}
+
 //命令man 7 signal提供了官方的信号介绍。
 //在POSIX.1-1990标准中定义的信号列表
 //    信号 值 动作 说明
@@ -1,81 +1,49 @@
 package bootstrap
 
 import (
+	"errors"
 	"log"
-	"sort"
-	"sync/atomic"
 
 	"github.com/walleframe/walle/app"
 )
 
-type priorityService struct {
-	app.Service
-	priority int
-}
+// 默认服务注册表
+var defaultRegistry = NewRegistry()
 
-var registerService []priorityService
-var startFlag atomic.Bool
+// Default get default service registry
+func Default() *Registry {
+	return defaultRegistry
+}
 
-// RegisterServiceByPriority regist service with specified priority.
-func RegisterServiceByPriority(priority int, svc app.Service) {
-	if startFlag.Load() {
-		log.Panic("application is already started, CAN NOT register service now")
-	}
-	if svc.Name() == "noop" {
-		log.Panicf("invalid service name, should REWRITE `Name() string` method. %#v", svc)
-	}
-	registerService = append(registerService, priorityService{
-		Service:  svc,
-		priority: priority,
-	})
+// RegisterServiceByPriority regist service with specified priority and depend services name.
+func RegisterServiceByPriority(priority int, svc app.Service, depends ...string) {
+	defaultRegistry.RegisterServiceByPriority(priority, svc, depends...)
 }
 
 // RegisterService register normal priority service
-func RegisterService(svc app.Service) {
-	RegisterServiceByPriority(500, svc)
+func RegisterService(svc app.Service, depends ...string) {
+	defaultRegistry.RegisterService(svc, depends...)
 }
 
 // RemoveService remove register service
 func RemoveService(svc app.Service) {
-	if startFlag.Load() {
-		return
-	}
-	for k, v := range registerService {
-		if v.Service == svc {
-			registerService = append(registerService[:k], registerService[k+1:]...)
-			break
-		}
-	}
+	defaultRegistry.RemoveService(svc)
 }
 
 // RemoveServiceByPriority remove register service by priority
 func RemoveServiceByPriority(priority int) {
-	if startFlag.Load() {
-		return
-	}
-	for k, v := range registerService {
-		if v.priority == priority {
-			registerService = append(registerService[:k], registerService[k+1:]...)
-			break
-		}
-	}
+	defaultRegistry.RemoveServiceByPriority(priority)
+}
+
+// Parallel 默认注册表是否并行初始化和启动服务
+func Parallel(enable bool) {
+	defaultRegistry.Parallel(enable)
 }
 
 // Run new app and run
-func Run() {
-	if startFlag.Load() {
-		return
-	}
-	startFlag.Store(true)
-	sort.Slice(registerService, func(i, j int) bool {
-		return registerService[i].priority < registerService[j].priority
-	})
-	service := make([]app.Service, 0, len(registerService))
-	for _, v := range registerService {
-		service = append(service, v.Service)
-	}
-	err := app.CreateApp(app.TeeService(service...)).Run()
-	if err != nil {
+func Run(opts ...app.AppOption) {
+	err := defaultRegistry.Run(opts...)
+	if err != nil && !errors.Is(err, ErrAlreadyStarted) {
 		log.Fatal(err)
 	}
 }
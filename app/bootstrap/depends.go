@@ -0,0 +1,213 @@
+package bootstrap
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/walleframe/walle/app"
+)
+
+// 依赖关系错误
+var (
+	ErrDependNotFound = errors.New("depend service not registered")
+	ErrDependCycle    = errors.New("depend service cycle")
+)
+
+// dependsOf 服务依赖的服务名称. 注册时指定的依赖和 app.Depender 接口返回的依赖合并.
+func (svc *priorityService) dependsOf() (depends []string) {
+	depends = append(depends, svc.depends...)
+	if d, ok := svc.Service.(app.Depender); ok {
+		depends = append(depends, d.Depends()...)
+	}
+	return
+}
+
+// sortServices 根据依赖关系构建DAG,计算服务初始化/启动顺序.
+// 依赖的服务总是先于当前服务启动; 没有依赖关系的服务之间按照优先级排序,优先级相同按照注册顺序.
+// 停止和清理顺序由 app.TeeService 逆序执行.
+func sortServices(list []priorityService) (sorted []app.Service, err error) {
+	idxs, err := sortIndexes(list)
+	if err != nil {
+		return
+	}
+	sorted = make([]app.Service, 0, len(idxs))
+	for _, idx := range idxs {
+		sorted = append(sorted, list[idx].Service)
+	}
+	return
+}
+
+// groupServices 计算服务顺序后,将相邻的优先级相同并且互相没有依赖关系的服务合并为 app.ParallelService 并行执行.
+func groupServices(list []priorityService) (grouped []app.Service, err error) {
+	groups, err := groupIndexes(list)
+	if err != nil {
+		return
+	}
+	grouped = make([]app.Service, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 1 {
+			grouped = append(grouped, list[group[0]].Service)
+			continue
+		}
+		svcs := make([]app.Service, 0, len(group))
+		for _, idx := range group {
+			svcs = append(svcs, list[idx].Service)
+		}
+		grouped = append(grouped, app.ParallelService(svcs...))
+	}
+	return
+}
+
+// groupIndexes 拓扑排序后分组,返回每组服务下标
+func groupIndexes(list []priorityService) (groups [][]int, err error) {
+	idxs, err := sortIndexes(list)
+	if err != nil {
+		return
+	}
+	var group []int
+	names := make(map[string]struct{})
+	for _, idx := range idxs {
+		split := len(group) > 0 && list[idx].priority != list[group[0]].priority
+		for _, name := range list[idx].dependsOf() {
+			if _, ok := names[name]; ok {
+				split = true
+				break
+			}
+		}
+		if split {
+			groups = append(groups, group)
+			group = nil
+			names = make(map[string]struct{})
+		}
+		group = append(group, idx)
+		names[list[idx].Name()] = struct{}{}
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return
+}
+
+// sortIndexes 拓扑排序,返回服务下标
+func sortIndexes(list []priorityService) (sorted []int, err error) {
+	names := make(map[string][]int, len(list))
+	for k := range list {
+		names[list[k].Name()] = append(names[list[k].Name()], k)
+	}
+	// build graph: depend -> services
+	edges := make([][]int, len(list))
+	indegree := make([]int, len(list))
+	for k := range list {
+		for _, name := range list[k].dependsOf() {
+			idxs, ok := names[name]
+			if !ok {
+				err = fmt.Errorf("service %s depend %s, %w", list[k].Name(), name, ErrDependNotFound)
+				return
+			}
+			for _, idx := range idxs {
+				if idx == k {
+					err = fmt.Errorf("service %s depend itself, %w", name, ErrDependCycle)
+					return
+				}
+				edges[idx] = append(edges[idx], k)
+				indegree[k]++
+			}
+		}
+	}
+	// topological sort, ready services sort by priority
+	ready := &readyQueue{list: list}
+	for k := range list {
+		if indegree[k] == 0 {
+			heap.Push(ready, k)
+		}
+	}
+	sorted = make([]int, 0, len(list))
+	for ready.Len() > 0 {
+		idx := heap.Pop(ready).(int)
+		sorted = append(sorted, idx)
+		for _, next := range edges[idx] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				heap.Push(ready, next)
+			}
+		}
+	}
+	if len(sorted) != len(list) {
+		err = fmt.Errorf("service %s, %w", findCycle(list, edges, indegree), ErrDependCycle)
+		sorted = nil
+	}
+	return
+}
+
+// findCycle 查找剩余节点中的依赖环,用于输出错误信息
+func findCycle(list []priorityService, edges [][]int, indegree []int) string {
+	// 0:unvisited 1:visiting 2:visited
+	state := make([]int, len(list))
+	path := make([]int, 0, len(list))
+	var cycle []int
+	var visit func(idx int) bool
+	visit = func(idx int) bool {
+		state[idx] = 1
+		path = append(path, idx)
+		for _, next := range edges[idx] {
+			if indegree[next] == 0 {
+				continue
+			}
+			if state[next] == 1 {
+				for k, v := range path {
+					if v == next {
+						cycle = append(cycle, path[k:]...)
+						cycle = append(cycle, next)
+						break
+					}
+				}
+				return true
+			}
+			if state[next] == 0 && visit(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		state[idx] = 2
+		return false
+	}
+	for k := range list {
+		if indegree[k] > 0 && state[k] == 0 && visit(k) {
+			break
+		}
+	}
+	// 按照依赖方向输出: a -> b 表示a依赖b
+	names := make([]string, 0, len(cycle))
+	for k := len(cycle) - 1; k >= 0; k-- {
+		names = append(names, list[cycle[k]].Name())
+	}
+	return strings.Join(names, " -> ")
+}
+
+// readyQueue 已满足依赖的服务队列,按照优先级和注册顺序排序
+type readyQueue struct {
+	list []priorityService
+	idxs []int
+}
+
+func (q *readyQueue) Len() int { return len(q.idxs) }
+
+func (q *readyQueue) Less(i, j int) bool {
+	a, b := q.idxs[i], q.idxs[j]
+	if q.list[a].priority != q.list[b].priority {
+		return q.list[a].priority < q.list[b].priority
+	}
+	return a < b
+}
+
+func (q *readyQueue) Swap(i, j int) { q.idxs[i], q.idxs[j] = q.idxs[j], q.idxs[i] }
+
+func (q *readyQueue) Push(x interface{}) { q.idxs = append(q.idxs, x.(int)) }
+
+func (q *readyQueue) Pop() (x interface{}) {
+	x = q.idxs[len(q.idxs)-1]
+	q.idxs = q.idxs[:len(q.idxs)-1]
+	return
+}
@@ -0,0 +1,173 @@
+package bootstrap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/app"
+)
+
+type testService struct {
+	app.NoopService
+	name    string
+	depends []string
+}
+
+func (svc *testService) Name() string {
+	return svc.name
+}
+
+func (svc *testService) Depends() []string {
+	return svc.depends
+}
+
+func newTestService(name string, priority int, depends ...string) priorityService {
+	return priorityService{
+		Service:  &testService{name: name},
+		priority: priority,
+		depends:  depends,
+	}
+}
+
+func serviceNames(list []app.Service) (names []string) {
+	for _, v := range list {
+		names = append(names, v.Name())
+	}
+	return
+}
+
+func TestSortServices(t *testing.T) {
+	datas := []struct {
+		name   string
+		list   []priorityService
+		expect []string
+		err    error
+	}{
+		{
+			name: "priority",
+			list: []priorityService{
+				newTestService("rpc", 910),
+				newTestService("redis", 40),
+				newTestService("config-manager", -1),
+				newTestService("logic", 500),
+			},
+			expect: []string{"config-manager", "redis", "logic", "rpc"},
+		},
+		{
+			name: "same priority keep register order",
+			list: []priorityService{
+				newTestService("a", 500),
+				newTestService("b", 500),
+				newTestService("c", 500),
+			},
+			expect: []string{"a", "b", "c"},
+		},
+		{
+			name: "depends before priority",
+			list: []priorityService{
+				newTestService("config-manager", -1),
+				newTestService("logic", 10, "redis", "config-manager"),
+				newTestService("redis", 500, "config-manager"),
+				newTestService("rpc", 20),
+			},
+			expect: []string{"config-manager", "rpc", "redis", "logic"},
+		},
+		{
+			name: "depends by interface",
+			list: []priorityService{
+				{Service: &testService{name: "logic", depends: []string{"redis"}}, priority: 1},
+				newTestService("redis", 500),
+			},
+			expect: []string{"redis", "logic"},
+		},
+		{
+			name: "not found",
+			list: []priorityService{
+				newTestService("logic", 500, "redis"),
+			},
+			err: ErrDependNotFound,
+		},
+		{
+			name: "depend self",
+			list: []priorityService{
+				newTestService("logic", 500, "logic"),
+			},
+			err: ErrDependCycle,
+		},
+		{
+			name: "cycle",
+			list: []priorityService{
+				newTestService("a", 500),
+				newTestService("b", 500, "a", "d"),
+				newTestService("c", 500, "b"),
+				newTestService("d", 500, "c"),
+			},
+			err: ErrDependCycle,
+		},
+	}
+	for _, v := range datas {
+		t.Run(v.name, func(t *testing.T) {
+			sorted, err := sortServices(v.list)
+			if v.err != nil {
+				assert.True(t, errors.Is(err, v.err), "error type", err)
+				t.Log(err)
+				return
+			}
+			assert.Nil(t, err, "sort services")
+			assert.EqualValues(t, v.expect, serviceNames(sorted), "sort result")
+		})
+	}
+}
+
+func TestGroupServices(t *testing.T) {
+	datas := []struct {
+		name   string
+		list   []priorityService
+		expect [][]string
+	}{
+		{
+			name: "same priority",
+			list: []priorityService{
+				newTestService("db", 30),
+				newTestService("redis", 30),
+				newTestService("config-manager", -1),
+				newTestService("xlsx", 30),
+			},
+			expect: [][]string{{"config-manager"}, {"db", "redis", "xlsx"}},
+		},
+		{
+			name: "depends split group",
+			list: []priorityService{
+				newTestService("a", 500),
+				newTestService("b", 500),
+				newTestService("c", 500, "a"),
+				newTestService("d", 500),
+			},
+			expect: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name: "different priority",
+			list: []priorityService{
+				newTestService("a", 1),
+				newTestService("b", 2),
+			},
+			expect: [][]string{{"a"}, {"b"}},
+		},
+	}
+	for _, v := range datas {
+		t.Run(v.name, func(t *testing.T) {
+			groups, err := groupIndexes(v.list)
+			assert.Nil(t, err, "group services")
+			var names [][]string
+			for _, group := range groups {
+				var list []string
+				for _, idx := range group {
+					list = append(list, v.list[idx].Name())
+				}
+				names = append(names, list)
+			}
+			assert.EqualValues(t, v.expect, names, "group result")
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package bootstrap
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/walleframe/walle/app"
+)
+
+type priorityService struct {
+	app.Service
+	priority int
+	depends  []string
+}
+
+// Registry 服务注册表. 每个注册表独立构建和运行应用,可以重复运行.
+type Registry struct {
+	mux      sync.Mutex
+	services []priorityService
+	parallel bool
+	started  atomic.Bool
+}
+
+// NewRegistry new service registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// RegisterServiceByPriority regist service with specified priority and depend services name.
+func (r *Registry) RegisterServiceByPriority(priority int, svc app.Service, depends ...string) {
+	if r.started.Load() {
+		log.Panic("application is already started, CAN NOT register service now")
+	}
+	if svc.Name() == "noop" {
+		log.Panicf("invalid service name, should REWRITE `Name() string` method. %#v", svc)
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.services = append(r.services, priorityService{
+		Service:  svc,
+		priority: priority,
+		depends:  depends,
+	})
+}
+
+// RegisterService register normal priority service
+func (r *Registry) RegisterService(svc app.Service, depends ...string) {
+	r.RegisterServiceByPriority(500, svc, depends...)
+}
+
+// RemoveService remove register service
+func (r *Registry) RemoveService(svc app.Service) {
+	if r.started.Load() {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for k, v := range r.services {
+		if v.Service == svc {
+			r.services = append(r.services[:k], r.services[k+1:]...)
+			break
+		}
+	}
+}
+
+// RemoveServiceByPriority remove register service by priority
+func (r *Registry) RemoveServiceByPriority(priority int) {
+	if r.started.Load() {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for k, v := range r.services {
+		if v.priority == priority {
+			r.services = append(r.services[:k], r.services[k+1:]...)
+			break
+		}
+	}
+}
+
+// Parallel 是否并行初始化和启动服务. 开启后相邻的优先级相同并且互相没有依赖关系的服务并行执行,
+// 任意服务失败时只回滚执行成功的服务.
+func (r *Registry) Parallel(enable bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.parallel = enable
+}
+
+// Services 按照依赖关系和优先级排序后的服务. 开启并行时,可以并行的服务合并为 app.ParallelService.
+func (r *Registry) Services() ([]app.Service, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.parallel {
+		return groupServices(r.services)
+	}
+	return sortServices(r.services)
+}
+
+// Build 使用已注册服务新建应用
+func (r *Registry) Build(opts ...app.AppOption) (*app.Application, error) {
+	services, err := r.Services()
+	if err != nil {
+		return nil, err
+	}
+	return app.CreateApp(app.TeeService(services...), opts...), nil
+}
+
+// Run 新建应用并运行,直到应用停止. 运行期间不能注册和删除服务.
+func (r *Registry) Run(opts ...app.AppOption) (err error) {
+	if !r.started.CompareAndSwap(false, true) {
+		return ErrAlreadyStarted
+	}
+	defer r.started.Store(false)
+	a, err := r.Build(opts...)
+	if err != nil {
+		return err
+	}
+	return a.Run()
+}
+
+// ErrAlreadyStarted registry application already running
+var ErrAlreadyStarted = errors.New("application is already started")
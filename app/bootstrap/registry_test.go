@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/app"
+)
+
+// 初始化后立即停止应用
+type stopService struct {
+	app.NoopService
+	name  string
+	count int
+}
+
+func (svc *stopService) Name() string {
+	return svc.name
+}
+
+func (svc *stopService) Start(s app.Stoper) error {
+	svc.count++
+	go s.Stop()
+	return nil
+}
+
+func TestRegistryRun(t *testing.T) {
+	r1, r2 := NewRegistry(), NewRegistry()
+	s1, s2 := &stopService{name: "s1"}, &stopService{name: "s2"}
+	r1.RegisterService(s1)
+	r2.RegisterService(s2)
+
+	for i := 0; i < 3; i++ {
+		wg := sync.WaitGroup{}
+		for _, r := range []*Registry{r1, r2} {
+			wg.Add(1)
+			go func(r *Registry) {
+				defer wg.Done()
+				assert.Nil(t, r.Run())
+			}(r)
+		}
+		wg.Wait()
+	}
+	assert.Equal(t, 3, s1.count)
+	assert.Equal(t, 3, s2.count)
+
+	r1.RemoveService(s1)
+	list, err := r1.Services()
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+	list, err = r2.Services()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"s2"}, serviceNames(list))
+}
+
+func TestRegistryDependNotFound(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterService(&stopService{name: "a"}, "b")
+	_, err := r.Build()
+	assert.ErrorIs(t, err, ErrDependNotFound)
+	assert.ErrorIs(t, r.Run(), ErrDependNotFound)
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"errors"
+)
+
+// HealthChecker 可选接口. 服务存活检查,返回错误表示服务异常.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// ReadyChecker 可选接口. 服务就绪检查,返回错误表示服务还不能处理请求.
+type ReadyChecker interface {
+	Ready(ctx context.Context) error
+}
+
+// ServiceHealth 单个服务检查结果
+type ServiceHealth struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// HealthStatus 应用检查结果汇总
+type HealthStatus struct {
+	OK       bool            `json:"ok"`
+	Services []ServiceHealth `json:"services,omitempty"`
+}
+
+// HealthReporter 应用健康状态查询接口. Application 实现此接口,
+// 服务可以在 Init(s Stoper) 时通过类型断言获取.
+type HealthReporter interface {
+	Health(ctx context.Context) HealthStatus
+	Ready(ctx context.Context) HealthStatus
+}
+
+var _ HealthReporter = (*Application)(nil)
+
+// 应用状态错误
+var (
+	ErrAppNotStarted = errors.New("application not started")
+	ErrAppStopping   = errors.New("application stopping")
+)
+
+// Health 检查已经初始化完成并且实现 HealthChecker 接口的服务
+func (app *Application) Health(ctx context.Context) (status HealthStatus) {
+	status.OK = true
+	app.rangeInited(func(svc Service) {
+		checker, ok := svc.(HealthChecker)
+		if !ok {
+			return
+		}
+		status.add(svc.Name(), checker.Health(ctx))
+	})
+	return
+}
+
+// Ready 应用启动完成,并且已经初始化完成的 ReadyChecker 服务都已就绪. 停止过程中不再就绪.
+func (app *Application) Ready(ctx context.Context) (status HealthStatus) {
+	status.OK = true
+	if app.stopping() {
+		status.add("application", ErrAppStopping)
+	} else if !app.started.Load() {
+		status.add("application", ErrAppNotStarted)
+	}
+	app.rangeInited(func(svc Service) {
+		checker, ok := svc.(ReadyChecker)
+		if !ok {
+			return
+		}
+		status.add(svc.Name(), checker.Ready(ctx))
+	})
+	return
+}
+
+// stopping 不读取信号chan,允许其他协程并发调用
+func (app *Application) stopping() bool {
+	select {
+	case <-app.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (status *HealthStatus) add(name string, err error) {
+	item := ServiceHealth{Name: name}
+	if err != nil {
+		item.Error = err.Error()
+		status.OK = false
+	}
+	status.Services = append(status.Services, item)
+}
+
+// serviceRanger 聚合服务遍历子服务
+type serviceRanger interface {
+	rangeServices(f func(svc Service))
+	rangeInited(f func(svc Service))
+}
+
+func rangeServices(svc Service, f func(svc Service)) {
+	if r, ok := svc.(serviceRanger); ok {
+		r.rangeServices(f)
+		return
+	}
+	f(svc)
+}
+
+func rangeInited(svc Service, f func(svc Service)) {
+	if r, ok := svc.(serviceRanger); ok {
+		r.rangeInited(f)
+		return
+	}
+	f(svc)
+}
+
+// rangeInited 遍历初始化完成的服务. Init执行中或者已经清理的服务不参与检查.
+func (app *Application) rangeInited(f func(svc Service)) {
+	if _, ok := app.svc.(serviceRanger); ok {
+		rangeInited(app.svc, f)
+		return
+	}
+	if app.inited.Load() {
+		f(app.svc)
+	}
+}
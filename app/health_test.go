@@ -0,0 +1,208 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/app"
+	"go.uber.org/atomic"
+)
+
+type checkService struct {
+	app.NoopService
+	name   string
+	health atomic.Error
+	ready  atomic.Error
+}
+
+func (svc *checkService) Name() string {
+	return svc.name
+}
+
+func (svc *checkService) Health(ctx context.Context) error {
+	return svc.health.Load()
+}
+
+func (svc *checkService) Ready(ctx context.Context) error {
+	return svc.ready.Load()
+}
+
+func TestApplicationHealth(t *testing.T) {
+	svc1 := &checkService{name: "svc1"}
+	svc2 := &checkService{name: "svc2"}
+	inited := make(chan struct{})
+	started := make(chan struct{})
+	a := app.CreateApp(app.TeeService(
+		svc1,
+		app.FuncService(
+			app.WithName("wait"),
+			app.WithInit(func(s app.Stoper) (err error) {
+				close(inited)
+				<-started
+				return nil
+			}),
+		),
+		svc2,
+	))
+	exit := make(chan error, 1)
+	go func() {
+		exit <- a.Run()
+	}()
+	<-inited
+	// initializing
+	ctx := context.Background()
+	status := a.Ready(ctx)
+	assert.False(t, status.OK, "not ready before start")
+	assert.EqualValues(t, app.ErrAppNotStarted.Error(), status.Services[0].Error)
+	assert.True(t, a.Health(ctx).OK, "health when initializing")
+	// svc2 not inited
+	svc2.health.Store(errors.New("not inited"))
+	assert.EqualValues(t, []app.ServiceHealth{{Name: "svc1"}}, a.Health(ctx).Services, "check inited service only")
+	svc2.health.Store(nil)
+	close(started)
+	// wait start
+	for i := 0; i < 100 && !a.Ready(ctx).OK; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, a.Ready(ctx).OK, "ready after start")
+	// service not ready
+	svc2.ready.Store(errors.New("not ready"))
+	status = a.Ready(ctx)
+	assert.False(t, status.OK, "service not ready")
+	assert.EqualValues(t, []app.ServiceHealth{
+		{Name: "svc1"}, {Name: "svc2", Error: "not ready"},
+	}, status.Services)
+	// service not health
+	svc1.health.Store(errors.New("broken"))
+	status = a.Health(ctx)
+	assert.False(t, status.OK, "service not health")
+	assert.EqualValues(t, []app.ServiceHealth{
+		{Name: "svc1", Error: "broken"}, {Name: "svc2"},
+	}, status.Services)
+	// stopping
+	svc2.ready.Store(nil)
+	a.Stop()
+	assert.False(t, a.Ready(ctx).OK, "not ready when stop")
+	select {
+	case err := <-exit:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("app not stop")
+	}
+}
+
+type reloadService struct {
+	app.NoopService
+	name   string
+	reload func() error
+}
+
+func (svc *reloadService) Name() string {
+	return svc.name
+}
+
+func (svc *reloadService) Reload(ctx context.Context) error {
+	return svc.reload()
+}
+
+func TestApplicationReload(t *testing.T) {
+	var mux sync.Mutex
+	var seq []string
+	getSeq := func() []string {
+		mux.Lock()
+		defer mux.Unlock()
+		return append([]string{}, seq...)
+	}
+	newService := func(name string, err error) app.Service {
+		return &reloadService{
+			name: name,
+			reload: func() error {
+				mux.Lock()
+				defer mux.Unlock()
+				seq = append(seq, name)
+				if name == "panic" {
+					panic("reload panic")
+				}
+				return err
+			},
+		}
+	}
+	failed := errors.New("reload failed")
+	a := app.CreateApp(app.TeeService(
+		newService("a", nil),
+		newService("failed", failed),
+		&app.NoopService{},
+		newService("panic", nil),
+		newService("b", nil),
+	))
+	err := a.Reload(context.Background())
+	assert.True(t, errors.Is(err, failed), "reload error")
+	assert.Contains(t, err.Error(), "reload panic")
+	assert.EqualValues(t, []string{"a", "failed", "panic", "b"}, getSeq(), "reload sequence")
+
+	// reload signal not stop application
+	reload := make(chan os.Signal, 1)
+	old := app.ReloadSignal
+	app.ReloadSignal = func(sig ...os.Signal) <-chan os.Signal { return reload }
+	defer func() { app.ReloadSignal = old }()
+	mux.Lock()
+	seq = seq[:0]
+	mux.Unlock()
+	exit := make(chan error, 1)
+	go func() {
+		exit <- a.Run()
+	}()
+	for i := 0; i < 100 && !a.Ready(context.Background()).OK; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	reload <- syscall.SIGHUP
+	for i := 0; i < 100 && len(getSeq()) < 4; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, a.IsStop(), "application stopped by reload")
+	assert.True(t, a.Ready(context.Background()).OK, "application ready after reload")
+	a.Stop()
+	select {
+	case err := <-exit:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("app not stop")
+	}
+	assert.EqualValues(t, []string{"a", "failed", "panic", "b"}, getSeq(), "signal reload sequence")
+}
+
+func TestApplication_SignalExit(t *testing.T) {
+	stop, reload := make(chan os.Signal, 1), make(chan os.Signal, 1)
+	oldStop, oldReload := app.StopSignal, app.ReloadSignal
+	app.StopSignal = func() <-chan os.Signal { return stop }
+	app.ReloadSignal = func(sig ...os.Signal) <-chan os.Signal { return reload }
+	defer func() {
+		app.StopSignal, app.ReloadSignal = oldStop, oldReload
+	}()
+	a := app.CreateApp(&app.NoopService{})
+	exit := make(chan error, 1)
+	go func() {
+		exit <- a.Run()
+	}()
+	for i := 0; i < 100 && !a.Ready(context.Background()).OK; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	// 停止信号退出,不经过Stop
+	stop <- syscall.SIGINT
+	select {
+	case err := <-exit:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("app not stop")
+	}
+	// Run返回之后不再监听重新加载信号
+	reload <- syscall.SIGHUP
+	time.Sleep(time.Millisecond * 20)
+	assert.Len(t, reload, 1, "reload watcher still running")
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// LifecyclePhase 服务生命周期阶段
+type LifecyclePhase string
+
+// 服务生命周期阶段
+const (
+	PhaseInit   LifecyclePhase = "init"
+	PhaseStart  LifecyclePhase = "start"
+	PhaseStop   LifecyclePhase = "stop"
+	PhaseFinish LifecyclePhase = "finish"
+	PhaseReload LifecyclePhase = "reload"
+)
+
+// LifecycleEvent 服务生命周期事件. 每个阶段开始时通知一次(Done=false),
+// 结束时通知一次(Done=true,附带耗时和错误).
+type LifecycleEvent struct {
+	// Service 服务名称
+	Service string
+	// Phase 生命周期阶段
+	Phase LifecyclePhase
+	// Done 阶段是否结束
+	Done bool
+	// Duration 阶段耗时
+	Duration time.Duration
+	// Err 阶段失败或者超时错误
+	Err error
+}
+
+// LifecycleObserver 服务生命周期观察者. 在服务调度协程中同步调用,不应阻塞.
+// ParallelService 的子服务在各自的协程中通知,所有通知串行执行,观察者不需要加锁.
+type LifecycleObserver interface {
+	OnLifecycle(evt *LifecycleEvent)
+}
+
+// LifecycleObserverFunc 函数实现 LifecycleObserver 接口
+type LifecycleObserverFunc func(evt *LifecycleEvent)
+
+func (f LifecycleObserverFunc) OnLifecycle(evt *LifecycleEvent) {
+	f(evt)
+}
+
+// StdLogObserver 使用标准库log输出生命周期日志(默认观察者)
+var StdLogObserver LifecycleObserver = LifecycleObserverFunc(func(evt *LifecycleEvent) {
+	switch {
+	case !evt.Done:
+		log.Println("service", evt.Service, "wait", evt.Phase)
+	case evt.Err != nil:
+		log.Printf("service %s %s failed:%v\n", evt.Service, evt.Phase, evt.Err)
+	default:
+		log.Println("service", evt.Service, evt.Phase, "finish", evt.Duration)
+	}
+})
+
+// AddObserver 添加生命周期观察者. 需要在 Run 之前调用.
+func (app *Application) AddObserver(obs ...LifecycleObserver) {
+	app.opts.Observers = append(app.opts.Observers, obs...)
+}
+
+// options 应用配置,服务通过 Stoper 获取
+func (app *Application) options() *AppOptions {
+	return app.opts
+}
+
+type optionsHolder interface {
+	options() *AppOptions
+}
+
+// stoperOptions 获取 Stoper 对应的应用配置
+func stoperOptions(s Stoper) *AppOptions {
+	if h, ok := s.(optionsHolder); ok {
+		return h.options()
+	}
+	return nil
+}
+
+// contextOptions 获取关闭流程context携带的应用配置
+func contextOptions(ctx context.Context) *AppOptions {
+	opts, _ := ctx.Value(appOptionsKey{}).(*AppOptions)
+	return opts
+}
+
+// optionsContext 携带应用配置的context
+func optionsContext(ctx context.Context, opts *AppOptions) context.Context {
+	if opts == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, appOptionsKey{}, opts)
+}
+
+// observePhase 执行服务生命周期阶段,并通知观察者. 没有应用配置时使用 StdLogObserver.
+func observePhase(opts *AppOptions, name string, phase LifecyclePhase, f func() error) (err error) {
+	observers := []LifecycleObserver{StdLogObserver}
+	if opts != nil {
+		observers = opts.Observers
+	}
+	notifyObservers(observers, &LifecycleEvent{Service: name, Phase: phase})
+	begin := time.Now()
+	err = f()
+	evt := &LifecycleEvent{
+		Service:  name,
+		Phase:    phase,
+		Done:     true,
+		Duration: time.Since(begin),
+		Err:      err,
+	}
+	notifyObservers(observers, evt)
+	return
+}
+
+// observerMux 串行通知观察者(ParallelService 并行执行子服务)
+var observerMux sync.Mutex
+
+func notifyObservers(observers []LifecycleObserver, evt *LifecycleEvent) {
+	observerMux.Lock()
+	defer observerMux.Unlock()
+	for _, o := range observers {
+		o.OnLifecycle(evt)
+	}
+}
@@ -0,0 +1,50 @@
+package app_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/app"
+)
+
+func TestApplicationObserver(t *testing.T) {
+	mux := sync.Mutex{}
+	var events []string
+	var failed *app.LifecycleEvent
+	observer := app.LifecycleObserverFunc(func(evt *app.LifecycleEvent) {
+		if !evt.Done {
+			return
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		events = append(events, evt.Service+"."+string(evt.Phase))
+		if evt.Err != nil {
+			failed = evt
+		}
+	})
+	startErr := errors.New("start failed")
+	a := app.CreateApp(app.TeeService(
+		app.FuncService(app.WithName("a")),
+		app.FuncService(app.WithName("b"), app.WithStart(func(app.Stoper) error {
+			time.Sleep(time.Millisecond)
+			return startErr
+		})),
+	), app.WithAppOptionObservers(observer))
+
+	assert.ErrorIs(t, a.Run(), startErr)
+	assert.Equal(t, []string{
+		"a.init", "b.init",
+		"a.start", "b.start",
+		"a.stop",
+		"b.finish", "a.finish",
+	}, events)
+	if assert.NotNil(t, failed) {
+		assert.Equal(t, "b", failed.Service)
+		assert.Equal(t, app.PhaseStart, failed.Phase)
+		assert.ErrorIs(t, failed.Err, startErr)
+		assert.GreaterOrEqual(t, failed.Duration, time.Millisecond)
+	}
+}
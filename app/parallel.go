@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+// 并行执行多个服务,服务之间不能有依赖关系. 任意服务失败时,只回滚执行成功的服务.
+type parallelService struct {
+	services []Service
+	initd    []Service
+	started  []Service
+	// 保护initd,健康检查可能在其他协程遍历
+	mux sync.RWMutex
+}
+
+// ParallelService 聚合多个相互独立的服务,并行初始化,启动,停止和清理.
+func ParallelService(svcs ...Service) Service {
+	return &parallelService{
+		services: svcs,
+		initd:    make([]Service, 0, len(svcs)),
+		started:  make([]Service, 0, len(svcs)),
+	}
+}
+
+func (p *parallelService) Name() string {
+	return "ParallelService"
+}
+
+// Init 并行初始化,失败时清理初始化成功的服务
+func (p *parallelService) Init(s Stoper) (err error) {
+	opts := stoperOptions(s)
+	initd, err := parallelRun(p.services, func(svc Service) error {
+		return runPhase(opts, svc, PhaseInit, func() error {
+			return svc.Init(s)
+		})
+	})
+	p.mux.Lock()
+	p.initd = initd
+	p.mux.Unlock()
+	if err != nil {
+		p.FinishContext(optionsContext(context.Background(), opts))
+	}
+	return
+}
+
+// Start 并行启动,失败时停止启动成功的服务
+func (p *parallelService) Start(s Stoper) (err error) {
+	opts := stoperOptions(s)
+	p.started, err = parallelRun(p.initd, func(svc Service) error {
+		return runPhase(opts, svc, PhaseStart, func() error {
+			return svc.Start(s)
+		})
+	})
+	if err != nil {
+		p.StopContext(optionsContext(context.Background(), opts))
+	}
+	return
+}
+
+// Stop 关闭
+func (p *parallelService) Stop() {
+	p.StopContext(context.Background())
+}
+
+// Finish 清理
+func (p *parallelService) Finish() {
+	p.FinishContext(context.Background())
+}
+
+// StopContext 并行关闭,每个服务使用独立的超时时间
+func (p *parallelService) StopContext(ctx context.Context) {
+	parallelRun(p.started, func(svc Service) error {
+		closePhase(ctx, svc, PhaseStop, stopService)
+		return nil
+	})
+	p.started = p.started[:0]
+}
+
+// FinishContext 并行清理,每个服务使用独立的超时时间
+func (p *parallelService) FinishContext(ctx context.Context) {
+	parallelRun(p.initd, func(svc Service) error {
+		closePhase(ctx, svc, PhaseFinish, finishService)
+		return nil
+	})
+	p.mux.Lock()
+	p.initd = p.initd[:0]
+	p.mux.Unlock()
+}
+
+// rangeServices 遍历所有子服务
+func (p *parallelService) rangeServices(f func(svc Service)) {
+	for _, v := range p.services {
+		rangeServices(v, f)
+	}
+}
+
+// rangeInited 遍历初始化完成的子服务
+func (p *parallelService) rangeInited(f func(svc Service)) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	for _, v := range p.initd {
+		rangeInited(v, f)
+	}
+}
+
+// parallelRun 并行执行f,返回执行成功的服务(保持原有顺序)和所有失败信息
+func parallelRun(svcs []Service, f func(svc Service) error) (succeed []Service, err error) {
+	errs := make([]error, len(svcs))
+	wg := sync.WaitGroup{}
+	for k, v := range svcs {
+		wg.Add(1)
+		go func(k int, svc Service) {
+			defer wg.Done()
+			errs[k] = f(svc)
+		}(k, v)
+	}
+	wg.Wait()
+	succeed = make([]Service, 0, len(svcs))
+	for k, v := range svcs {
+		if errs[k] != nil {
+			err = multierr.Append(err, errs[k])
+			continue
+		}
+		succeed = append(succeed, v)
+	}
+	return
+}
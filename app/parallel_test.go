@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/app"
+)
+
+func TestParallelService_Rollback(t *testing.T) {
+	mux := sync.Mutex{}
+	var finished []string
+	// a,c 初始化成功, b 等待a,c开始初始化之后失败
+	ready := sync.WaitGroup{}
+	ready.Add(2)
+	newSvc := func(name string, err error) app.Service {
+		return app.FuncService(
+			app.WithName(name),
+			app.WithInit(func(app.Stoper) error {
+				if err != nil {
+					ready.Wait()
+					return err
+				}
+				ready.Done()
+				return nil
+			}),
+			app.WithStart(func(app.Stoper) error {
+				t.Errorf("service %s should not start", name)
+				return nil
+			}),
+			app.WithFinish(func() {
+				mux.Lock()
+				finished = append(finished, name)
+				mux.Unlock()
+			}),
+		)
+	}
+	initErr := errors.New("init failed")
+	a := app.CreateApp(app.TeeService(
+		app.ParallelService(newSvc("a", nil), newSvc("b", initErr), newSvc("c", nil)),
+	))
+	err := a.Run()
+	assert.ErrorIs(t, err, initErr)
+	assert.ElementsMatch(t, []string{"a", "c"}, finished)
+}
+
+func TestParallelService_Observer(t *testing.T) {
+	// 观察者不加锁,通知需要串行执行
+	var running, events int
+	overlap := false
+	obs := app.LifecycleObserverFunc(func(evt *app.LifecycleEvent) {
+		running++
+		if running > 1 {
+			overlap = true
+		}
+		time.Sleep(time.Millisecond)
+		events++
+		running--
+	})
+	svcs := make([]app.Service, 4)
+	for k := range svcs {
+		svcs[k] = app.FuncService(
+			app.WithName(fmt.Sprintf("svc%d", k)),
+			app.WithStart(func(s app.Stoper) error {
+				s.Stop()
+				return nil
+			}),
+		)
+	}
+	a := app.CreateApp(app.ParallelService(svcs...), app.WithAppOptionObservers(obs))
+	assert.Nil(t, a.Run())
+	assert.False(t, overlap, "observer called concurrently")
+	// init,start,stop,finish 开始和结束各通知一次
+	assert.Equal(t, len(svcs)*8, events)
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/multierr"
+)
+
+// Reloader 可选接口. 收到重新加载信号时调用(日志级别,配置数据等),返回错误不会导致应用停止.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// Reload 按照服务启动顺序通知所有实现 Reloader 接口的服务重新加载.
+// 某个服务重新加载失败不影响其他服务,返回所有失败信息.
+func (app *Application) Reload(ctx context.Context) (err error) {
+	rangeServices(app.svc, func(svc Service) {
+		reloader, ok := svc.(Reloader)
+		if !ok {
+			return
+		}
+		rerr := observePhase(app.opts, svc.Name(), PhaseReload, func() error {
+			return reloadService(ctx, reloader)
+		})
+		if rerr != nil {
+			err = multierr.Append(err, fmt.Errorf("service %s reload failed:%w", svc.Name(), rerr))
+		}
+	})
+	return
+}
+
+// watchReload 监听重新加载信号,直到应用停止或者Run返回
+func (app *Application) watchReload(reload <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-app.stop:
+			return
+		case <-done:
+			return
+		case <-reload:
+			app.reload()
+		}
+	}
+}
+
+// reload 响应重新加载信号
+func (app *Application) reload() {
+	if !app.started.Load() || app.stopping() {
+		log.Println("application not running, ignore reload signal")
+		return
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if app.opts.ReloadTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), app.opts.ReloadTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+	log.Println("application reload")
+	if err := app.Reload(ctx); err != nil {
+		log.Println("application reload failed", err)
+		return
+	}
+	log.Println("application reload success")
+}
+
+// reloadService 重新加载服务,服务panic转换为错误
+func reloadService(ctx context.Context, svc Reloader) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return svc.Reload(ctx)
+}
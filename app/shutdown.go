@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+type appOptionsKey struct{}
+
+type stopTimeoutsKey struct{}
+
+// stopTimeouts 停止超时的服务(Service.Name()). Stop仍在后台执行,不再执行Finish.
+type stopTimeouts struct {
+	mux   sync.Mutex
+	names map[string]struct{}
+}
+
+// shutdownStateContext 记录关闭流程中停止超时的服务
+func shutdownStateContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stopTimeoutsKey{}, &stopTimeouts{names: make(map[string]struct{})})
+}
+
+func markStopTimeout(ctx context.Context, svc Service) {
+	st, ok := ctx.Value(stopTimeoutsKey{}).(*stopTimeouts)
+	if !ok {
+		return
+	}
+	st.mux.Lock()
+	st.names[svc.Name()] = struct{}{}
+	st.mux.Unlock()
+}
+
+func isStopTimeout(ctx context.Context, svc Service) bool {
+	st, ok := ctx.Value(stopTimeoutsKey{}).(*stopTimeouts)
+	if !ok {
+		return false
+	}
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	_, ok = st.names[svc.Name()]
+	return ok
+}
+
+// serviceContext 根据应用配置,生成单个服务的关闭超时context
+func serviceContext(ctx context.Context, svc Service) (context.Context, context.CancelFunc) {
+	opts := contextOptions(ctx)
+	if opts == nil {
+		return context.WithCancel(ctx)
+	}
+	timeout := opts.ServiceStopTimeout
+	if v, ok := opts.ServiceStopTimeouts[svc.Name()]; ok {
+		timeout = v
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// stopService 停止服务,服务实现了 ContextStoper 接口则传递ctx
+func stopService(ctx context.Context, svc Service) {
+	if s, ok := svc.(ContextStoper); ok {
+		s.StopContext(ctx)
+		return
+	}
+	svc.Stop()
+}
+
+// finishService 清理服务,服务实现了 ContextFinisher 接口则传递ctx
+func finishService(ctx context.Context, svc Service) {
+	if s, ok := svc.(ContextFinisher); ok {
+		s.FinishContext(ctx)
+		return
+	}
+	svc.Finish()
+}
+
+// waitContext 等待f执行完成或者ctx超时. 超时返回ctx错误,f在后台继续执行.
+func waitContext(ctx context.Context, f func()) error {
+	if ctx.Done() == nil {
+		f()
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
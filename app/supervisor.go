@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SupervisorOption supervisor options
+//
+//go:generate gogen option -n SupervisorOption -f Supervisor -o option.supervisor.go
+func walleSupervisor() interface{} {
+	return map[string]interface{}{
+		// MinBackoff 首次重启等待时间
+		"MinBackoff": time.Duration(time.Millisecond * 100),
+		// MaxBackoff 最大重启等待时间
+		"MaxBackoff": time.Duration(time.Second * 30),
+		// BackoffFactor 每次重启等待时间倍数
+		"BackoffFactor": float64(2),
+		// MaxRestarts 连续失败重启次数上限,超过之后停止应用. <0 表示不限制
+		"MaxRestarts": int(10),
+		// ResetAfter worker运行超过此时间后失败,重置重启等待时间和连续失败次数. 0表示不重置
+		"ResetAfter": time.Duration(time.Minute),
+		// RestartOnSuccess worker正常返回(nil)时也重新启动
+		"RestartOnSuccess": false,
+	}
+}
+
+// ErrWorkerPanic worker panic
+var ErrWorkerPanic = errors.New("supervisor worker panic")
+
+// ErrRestartExhausted 重启次数超过上限
+var ErrRestartExhausted = errors.New("supervisor restart exhausted")
+
+// SupervisorWorker 后台循环任务. ctx 在服务停止时取消.
+type SupervisorWorker func(ctx context.Context) error
+
+// Supervisor 监管后台任务的服务. worker 返回错误或者panic时按照指数退避重启,
+// 连续失败次数超过上限之后停止应用.
+type Supervisor struct {
+	name   string
+	worker SupervisorWorker
+	opts   *SupervisorOptions
+	// restart count
+	restarts atomic.Int64
+	mux      sync.Mutex
+	lastErr  error
+	cancel   func()
+	done     chan struct{}
+}
+
+var _ Service = (*Supervisor)(nil)
+
+// NewSupervisor new supervisor service
+func NewSupervisor(name string, worker SupervisorWorker, opts ...SupervisorOption) *Supervisor {
+	return &Supervisor{
+		name:   name,
+		worker: worker,
+		opts:   NewSupervisorOptions(opts...),
+	}
+}
+
+func (s *Supervisor) Name() string {
+	return s.name
+}
+
+// Init 初始化
+func (s *Supervisor) Init(Stoper) (err error) {
+	return
+}
+
+// Start 启动worker
+func (s *Supervisor) Start(stoper Stoper) (err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, stoper)
+	return
+}
+
+// Stop 停止worker并等待退出
+func (s *Supervisor) Stop() {
+	s.StopContext(context.Background())
+}
+
+// StopContext 停止worker并等待退出,ctx超时后不再等待
+func (s *Supervisor) StopContext(ctx context.Context) {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+	}
+}
+
+// Finish 清理
+func (s *Supervisor) Finish() {
+}
+
+// Restarts worker重启总次数
+func (s *Supervisor) Restarts() int64 {
+	return s.restarts.Load()
+}
+
+// LastError worker最后一次返回的错误
+func (s *Supervisor) LastError() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.lastErr
+}
+
+// Health 重启次数耗尽时返回错误
+func (s *Supervisor) Health(ctx context.Context) error {
+	err := s.LastError()
+	if errors.Is(err, ErrRestartExhausted) {
+		return err
+	}
+	return nil
+}
+
+func (s *Supervisor) run(ctx context.Context, stoper Stoper) {
+	defer close(s.done)
+	backoff := s.opts.MinBackoff
+	failures := 0
+	for {
+		begin := time.Now()
+		err := s.call(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && !s.opts.RestartOnSuccess {
+			log.Printf("supervisor %s worker finished\n", s.name)
+			return
+		}
+		if s.opts.ResetAfter > 0 && time.Since(begin) >= s.opts.ResetAfter {
+			backoff = s.opts.MinBackoff
+			failures = 0
+		}
+		failures++
+		if s.opts.MaxRestarts >= 0 && failures > s.opts.MaxRestarts {
+			err = fmt.Errorf("%w, service %s failed %d times, last error: %v", ErrRestartExhausted, s.name, failures, err)
+			s.setLastError(err)
+			log.Println(err)
+			stoper.Stop()
+			return
+		}
+		s.setLastError(err)
+		s.restarts.Add(1)
+		log.Printf("supervisor %s worker exit, restart after %v, %v\n", s.name, backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = time.Duration(float64(backoff) * s.opts.BackoffFactor)
+		if backoff > s.opts.MaxBackoff {
+			backoff = s.opts.MaxBackoff
+		}
+	}
+}
+
+// call 执行worker,捕获panic
+func (s *Supervisor) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v\n%s", ErrWorkerPanic, r, debug.Stack())
+		}
+	}()
+	return s.worker(ctx)
+}
+
+func (s *Supervisor) setLastError(err error) {
+	s.mux.Lock()
+	s.lastErr = err
+	s.mux.Unlock()
+}
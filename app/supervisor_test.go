@@ -0,0 +1,70 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/app"
+	"go.uber.org/atomic"
+)
+
+func TestSupervisor_Restart(t *testing.T) {
+	count := atomic.NewInt32(0)
+	running := make(chan struct{})
+	sup := app.NewSupervisor("worker", func(ctx context.Context) error {
+		switch count.Inc() {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("panic")
+		}
+		close(running)
+		<-ctx.Done()
+		return nil
+	}, app.WithSupervisorOptionMinBackoff(time.Millisecond))
+
+	a := app.CreateApp(sup)
+	go func() {
+		<-running
+		a.Stop()
+	}()
+	select {
+	case <-runAsync(a):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timeout")
+	}
+	assert.EqualValues(t, 3, count.Load())
+	assert.EqualValues(t, 2, sup.Restarts())
+	assert.ErrorIs(t, sup.LastError(), app.ErrWorkerPanic)
+	assert.Nil(t, sup.Health(context.Background()))
+}
+
+func TestSupervisor_Exhausted(t *testing.T) {
+	sup := app.NewSupervisor("worker", func(ctx context.Context) error {
+		return errors.New("failed")
+	},
+		app.WithSupervisorOptionMinBackoff(time.Millisecond),
+		app.WithSupervisorOptionMaxRestarts(3),
+	)
+	// 重启次数耗尽后停止应用
+	select {
+	case <-runAsync(app.CreateApp(sup)):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timeout, app not stop")
+	}
+	assert.EqualValues(t, 3, sup.Restarts())
+	assert.ErrorIs(t, sup.LastError(), app.ErrRestartExhausted)
+	assert.ErrorIs(t, sup.Health(context.Background()), app.ErrRestartExhausted)
+}
+
+func runAsync(a *app.Application) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		a.Run()
+		close(done)
+	}()
+	return done
+}
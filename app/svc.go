@@ -1,8 +1,10 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sync"
 )
 
 // Service 接口 是基础服务对象容器. 负责 各个基础服务对象的状态维护
@@ -16,11 +18,29 @@ type Service interface {
 	Finish()
 }
 
+// ContextStoper 可选接口. 服务停止时接收关闭超时context,超时之后不再等待服务停止.
+type ContextStoper interface {
+	StopContext(ctx context.Context)
+}
+
+// ContextFinisher 可选接口. 服务清理时接收关闭超时context,超时之后不再等待服务清理.
+type ContextFinisher interface {
+	FinishContext(ctx context.Context)
+}
+
+// Depender 可选接口. 服务声明依赖的其他服务名称(Service.Name()),
+// bootstrap 根据依赖关系决定初始化和启动顺序.
+type Depender interface {
+	Depends() []string
+}
+
 // 聚合多个服务。正序启动，逆序清理
 type teeService struct {
 	services []Service
 	initd    []Service
 	started  []Service
+	// 保护initd,健康检查可能在其他协程遍历
+	mux sync.RWMutex
 }
 
 // TeeService 聚合多个服务。正序启动，逆序清理
@@ -38,62 +58,124 @@ func (t *teeService) Name() string {
 
 // Init 初始化
 func (t *teeService) Init(s Stoper) (err error) {
+	opts := stoperOptions(s)
 	for _, v := range t.services {
-		log.Printf("service %s wait init %T\n", v.Name(), v)
-		err = v.Init(s)
+		err = runPhase(opts, v, PhaseInit, func() error {
+			return v.Init(s)
+		})
 		if err != nil {
-			err = fmt.Errorf("service %s init failed:%w", v.Name(), err)
-			log.Println(err)
-			t.Finish()
+			t.FinishContext(optionsContext(context.Background(), opts))
 			return
 		}
+		t.mux.Lock()
 		t.initd = append(t.initd, v)
+		t.mux.Unlock()
 		if s.IsStop() {
 			return
 		}
-		log.Println("service", v.Name(), "init finish")
 	}
 	return
 }
 
 // Start 启动
 func (t *teeService) Start(s Stoper) (err error) {
+	opts := stoperOptions(s)
 	for _, v := range t.initd {
-		log.Println("service", v.Name(), "wait start", fmt.Sprintf("%T", v))
-		err = v.Start(s)
+		err = runPhase(opts, v, PhaseStart, func() error {
+			return v.Start(s)
+		})
 		if err != nil {
-			err = fmt.Errorf("service %s start failed:%w", v.Name(), err)
-			log.Println(err)
-			t.Stop()
+			t.StopContext(optionsContext(context.Background(), opts))
 			return
 		}
 		t.started = append(t.started, v)
 		if s.IsStop() {
 			return
 		}
-		log.Println("service", v.Name(), "start finish")
 	}
 	return
 }
 
 // Stop 关闭
 func (t *teeService) Stop() {
+	t.StopContext(context.Background())
+}
+
+// Finish 清理
+func (t *teeService) Finish() {
+	t.FinishContext(context.Background())
+}
+
+// rangeServices 遍历所有子服务
+func (t *teeService) rangeServices(f func(svc Service)) {
+	for _, v := range t.services {
+		rangeServices(v, f)
+	}
+}
+
+// rangeInited 遍历初始化完成的子服务
+func (t *teeService) rangeInited(f func(svc Service)) {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+	for _, v := range t.initd {
+		rangeInited(v, f)
+	}
+}
+
+// StopContext 关闭,每个服务使用独立的超时时间
+func (t *teeService) StopContext(ctx context.Context) {
 	for k := len(t.started) - 1; k >= 0; k-- {
-		log.Println("service", t.started[k].Name(), "stop")
-		t.started[k].Stop()
+		closePhase(ctx, t.started[k], PhaseStop, stopService)
 	}
 	return
 }
 
-// Finish 清理
-func (t *teeService) Finish() {
+// FinishContext 清理,每个服务使用独立的超时时间
+func (t *teeService) FinishContext(ctx context.Context) {
 	for k := len(t.initd) - 1; k >= 0; k-- {
-		log.Println("service", t.initd[k].Name(), "finish")
-		t.initd[k].Finish()
+		closePhase(ctx, t.initd[k], PhaseFinish, finishService)
+	}
+	t.mux.Lock()
+	t.initd = t.initd[:0]
+	t.mux.Unlock()
+	return
+}
+
+// runPhase 执行子服务初始化/启动,通知观察者.
+// 聚合服务(TeeService,ParallelService)由子服务各自通知,错误不再包装.
+func runPhase(opts *AppOptions, svc Service, phase LifecyclePhase, f func() error) (err error) {
+	if _, ok := svc.(serviceRanger); ok {
+		return f()
+	}
+	err = observePhase(opts, svc.Name(), phase, f)
+	if err != nil {
+		err = fmt.Errorf("service %s %s failed:%w", svc.Name(), phase, err)
 	}
 	return
 }
 
+// closePhase 执行子服务停止/清理,每个服务使用独立的超时时间,通知观察者.
+func closePhase(ctx context.Context, svc Service, phase LifecyclePhase, f func(ctx context.Context, svc Service)) {
+	if _, ok := svc.(serviceRanger); ok {
+		f(ctx, svc)
+		return
+	}
+	// Stop超时的服务仍在后台执行Stop,不能同时执行Finish
+	if phase == PhaseFinish && isStopTimeout(ctx, svc) {
+		log.Printf("service %s stop timeout, skip finish\n", svc.Name())
+		return
+	}
+	sctx, cancel := serviceContext(ctx, svc)
+	defer cancel()
+	observePhase(contextOptions(ctx), svc.Name(), phase, func() error {
+		err := waitContext(sctx, func() { f(sctx, svc) })
+		if err != nil && phase == PhaseStop {
+			markStopTimeout(ctx, svc)
+		}
+		return err
+	})
+}
+
 // FuncService for functions
 //
 //go:generate gogen option -n FuncSvcOption -o option.go
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"testing"
+	"time"
 
 	"github.com/walleframe/walle/app"
 	"github.com/walleframe/walle/testpkg/mock_app"
@@ -232,3 +233,81 @@ func testTeeCheck(t *testing.T, mc *gomock.Controller, num, initFailedIdx, start
 	}
 	return
 }
+
+func TestTeeService_StopTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	stopped := atomic.Int32{}
+	slowFinish := atomic.Bool{}
+	svcs := []app.Service{
+		app.FuncService(
+			app.WithName("fast"),
+			app.WithStop(func() {
+				stopped.Inc()
+			}),
+			app.WithFinish(func() {
+				stopped.Inc()
+			}),
+		),
+		app.FuncService(
+			app.WithName("slow"),
+			app.WithStop(func() {
+				<-block
+			}),
+			app.WithFinish(func() {
+				slowFinish.Store(true)
+			}),
+		),
+	}
+	forceExit := atomic.Bool{}
+	start := time.Now()
+	ret := runApp(t, app.CreateApp(app.TeeService(svcs...),
+		app.WithAppOptionServiceStopTimeout(time.Second),
+		app.WithAppOptionServiceStopTimeouts(map[string]time.Duration{
+			"slow": time.Millisecond * 20,
+		}),
+		app.WithAppOptionForceExit(func() {
+			forceExit.Store(true)
+		}),
+	), true)
+	if ret != nil {
+		t.Fatal(ret)
+	}
+	if use := time.Since(start); use > time.Second {
+		t.Fatal("stop timeout not work", use)
+	}
+	if stopped.Load() != 2 {
+		t.Fatal("fast service not stop and finish", stopped.Load())
+	}
+	if forceExit.Load() {
+		t.Fatal("should not force exit")
+	}
+	// Stop超时的服务不执行Finish
+	if slowFinish.Load() {
+		t.Fatal("slow service finish called while stop running")
+	}
+}
+
+func TestApplication_ForceExit(t *testing.T) {
+	block := make(chan struct{})
+	forceExit := atomic.Bool{}
+	svc := app.FuncService(
+		app.WithName("blocked"),
+		app.WithStop(func() {
+			<-block
+		}),
+	)
+	ret := runApp(t, app.CreateApp(svc,
+		app.WithAppOptionForceExitTimeout(time.Millisecond*20),
+		app.WithAppOptionForceExit(func() {
+			forceExit.Store(true)
+			close(block)
+		}),
+	), true)
+	if ret != nil {
+		t.Fatal(ret)
+	}
+	if !forceExit.Load() {
+		t.Fatal("force exit not called")
+	}
+}
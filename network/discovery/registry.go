@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/walleframe/walle/kvstore"
 	"github.com/walleframe/walle/util"
+	"github.com/walleframe/walle/util/metrics"
 	"github.com/walleframe/walle/zaplog"
 	"go.uber.org/zap"
 )
@@ -56,6 +57,14 @@ func walleRegistry() interface{} {
 	}
 }
 
+// 注册状态监控指标
+var (
+	metricRegistryOnline = metrics.NewGauge("walle_discovery_registry_online",
+		"registry entry status, 1 online 0 offline", "path")
+	metricRegistryErrors = metrics.NewCounter("walle_discovery_registry_errors_total",
+		"registry store operation failed", "path", "op")
+)
+
 type registry struct {
 	opts  *RegistryOptions
 	entry Entry
@@ -90,11 +99,14 @@ func (r *registry) Online(ctx context.Context) (err error) {
 	err = r.store.Put(ctx, kvstore.Join(r.path, string(key)), value)
 	if err != nil {
 		r.opts.FrameLogger.New("Registry.Online").Error("put failed", zap.Error(err))
+		metricRegistryErrors.With(r.path, "online").Inc()
+		return
 	}
+	metricRegistryOnline.With(r.path).Set(1)
 	return
 }
 func (r *registry) Offline(ctx context.Context) (err error) {
-	r.entry.ModifyState(EntryStateOnline)
+	r.entry.ModifyState(EntryStateOffline)
 	key, value, err := r.opts.Codec.Mashal(r.entry)
 	if err != nil {
 		r.opts.FrameLogger.New("Registry.Offline").Error("marshal failed", zap.Error(err))
@@ -103,7 +115,10 @@ func (r *registry) Offline(ctx context.Context) (err error) {
 	err = r.store.Put(ctx, kvstore.Join(r.path, string(key)), value)
 	if err != nil {
 		r.opts.FrameLogger.New("Registry.Offline").Error("put failed", zap.Error(err))
+		metricRegistryErrors.With(r.path, "offline").Inc()
+		return
 	}
+	metricRegistryOnline.With(r.path).Set(0)
 	return
 }
 
@@ -116,6 +131,9 @@ func (r *registry) Clean(ctx context.Context) (err error) {
 	err = r.store.Delete(ctx, kvstore.Join(r.path, string(key)))
 	if err != nil {
 		r.opts.FrameLogger.New("Registry.Clean").Error("delete failed", zap.Error(err))
+		metricRegistryErrors.With(r.path, "clean").Inc()
+		return
 	}
+	metricRegistryOnline.Delete(r.path)
 	return
 }
@@ -0,0 +1,45 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/kvstore"
+)
+
+// putStore 只记录Put写入的数据
+type putStore struct {
+	kvstore.Store
+	values map[string][]byte
+}
+
+func (s *putStore) Put(ctx context.Context, key string, value []byte, opts ...kvstore.WriteOption) error {
+	s.values[key] = value
+	return nil
+}
+
+func TestRegistry_State(t *testing.T) {
+	store := &putStore{values: make(map[string][]byte)}
+	r := NewRegistry("/svc", store)
+	ctx := context.Background()
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:8080")
+	assert.Nil(t, err)
+	assert.Nil(t, r.NewEntry(ctx, addr))
+
+	state := func() EntryState {
+		assert.Len(t, store.values, 1)
+		for key, value := range store.values {
+			node := &Node{}
+			assert.Nil(t, NodeJsonEntryCodec.Unmarshal(node, key, value))
+			return node.State()
+		}
+		return -1
+	}
+	assert.Nil(t, r.Online(ctx))
+	assert.Equal(t, EntryStateOnline, state())
+	// 下线状态写入存储
+	assert.Nil(t, r.Offline(ctx))
+	assert.Equal(t, EntryStateOffline, state())
+}
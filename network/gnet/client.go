@@ -82,6 +82,7 @@ type GNetClient struct {
 }
 
 var _ network.Link = &GNetClient{}
+var _ network.StreamCaller = &GNetClient{}
 
 // NewClientEx 创建客户端
 // inner *process.InnerOptions 选项应该由上层ClientProxy去决定如何设置。
@@ -329,6 +330,8 @@ func (p *goClientContextPool) NewContext(inner *process.InnerOptions, opts *proc
 	ctx.LoadFlag = loadFlag
 	ctx.Log = opts.Logger
 	ctx.FreeContext = ctx
+	ctx.RouterParams = nil
+	ctx.CancelTimeout = nil
 	ctx.GNetClient = inner.BindData.(*GNetClient)
 	return ctx
 }
@@ -105,6 +105,7 @@ type GNetServer struct {
 	clients    map[*GNetSession]Session
 	udp        bool
 	initNotify chan error
+	serving    atomic.Bool
 }
 
 func NewServer(opts ...ServerOption) *GNetServer {
@@ -126,6 +127,7 @@ func (svr *GNetServer) Run(addr string) (err error) {
 		svr.udp = true
 	}
 	opts := convertServerOptions(svr.opts)
+	defer network.RegisterServerMetrics("gnet", addr, svr)()
 	return gnet.Run(svr, addr, opts...)
 }
 
@@ -202,6 +204,11 @@ func (s *GNetServer) ForEach(f func(Session)) {
 	}
 }
 
+// Serving engine booted and accepting connections
+func (s *GNetServer) Serving() bool {
+	return s.serving.Load()
+}
+
 func (s *GNetServer) Shutdown(ctx context.Context) (err error) {
 	gnet.Stop(ctx, s.opts.Addr)
 	s.mux.Lock()
@@ -216,6 +223,7 @@ func (s *GNetServer) Shutdown(ctx context.Context) (err error) {
 // OnBoot fires when the engine is ready for accepting connections.
 // The parameter engine has information and various utilities.
 func (svr *GNetServer) OnBoot(eng gnet.Engine) (action gnet.Action) {
+	svr.serving.Store(true)
 	if svr.initNotify != nil {
 		svr.initNotify <- nil
 	}
@@ -226,6 +234,7 @@ func (svr *GNetServer) OnBoot(eng gnet.Engine) (action gnet.Action) {
 // all event-loops and connections are closed.
 
 func (svr *GNetServer) OnShutdown(eng gnet.Engine) {
+	svr.serving.Store(false)
 	if svr.initNotify != nil {
 		svr.initNotify <- errors.New("init failed")
 	}
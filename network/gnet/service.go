@@ -2,6 +2,7 @@ package gnet
 
 import (
 	"context"
+	"errors"
 	"net"
 
 	"github.com/walleframe/walle/app"
@@ -39,6 +40,20 @@ func (svc *GNetService) Stop() {
 	svc.svr.Shutdown(context.Background())
 	return
 }
+func (svc *GNetService) StopContext(ctx context.Context) {
+	svc.svr.Shutdown(ctx)
+	return
+}
 func (svc *GNetService) Finish() {
 	return
 }
+
+// Ready 服务正在接收连接
+func (svc *GNetService) Ready(ctx context.Context) error {
+	if !svc.svr.Serving() {
+		return ErrNotServing
+	}
+	return nil
+}
+
+var ErrNotServing = errors.New("server not serving")
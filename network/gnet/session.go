@@ -129,6 +129,8 @@ func (p *gnetServerContextPool) NewContext(inner *process.InnerOptions, opts *pr
 	ctx.LoadFlag = loadFlag
 	ctx.Log = opts.Logger
 	ctx.FreeContext = ctx
+	ctx.RouterParams = nil
+	ctx.CancelTimeout = nil
 	ctx.GNetSession = inner.BindData.(*GNetSession)
 	return ctx
 }
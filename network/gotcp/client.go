@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/walleframe/walle/network"
 	"github.com/walleframe/walle/network/rpc"
 	"github.com/walleframe/walle/process"
 	"github.com/walleframe/walle/process/errcode"
@@ -79,6 +80,10 @@ func walleClient() interface{} {
 		"MaxMessageSizeLimit": int(0),
 		// BlockConnect 创建客户端时候，是否阻塞等待链接服务器
 		"BlockConnect": true,
+		// Handshake 链接建立之后执行握手(例如 secure.ClientHandshake),返回会话独立的编码器. nil 不握手
+		"Handshake": Handshake(nil),
+		// HandshakeTimeout 握手超时时间
+		"HandshakeTimeout": time.Duration(time.Second * 10),
 	}
 }
 
@@ -99,6 +104,8 @@ type GoClient struct {
 	//
 	writeMethod WriteMethod
 	opts        *ClientOptions
+	// 握手之后替换会话编码器
+	encoder *network.HandshakeEncoder
 	// close call back
 	closeChain []func(Client)
 }
@@ -122,12 +129,13 @@ func NewClientEx(inner *process.InnerOptions, copts *ClientOptions) (cli *GoClie
 		inner.Router = copts.Router
 	}
 
-	cli = &GoClient{
-		RPCProcess: rpc.NewRPCProcess(
-			inner,
-			process.NewProcessOptions(copts.ProcessOptions...),
-		),
+	procOpts := process.NewProcessOptions(copts.ProcessOptions...)
+	cli = &GoClient{}
+	if copts.Handshake != nil {
+		cli.encoder = network.NewHandshakeEncoder(procOpts.PacketEncode)
+		procOpts.PacketEncode = cli.encoder
 	}
+	cli.RPCProcess = rpc.NewRPCProcess(inner, procOpts)
 	cli.Inner.ApplyOption(
 		process.WithInnerOptionOutput(cli),
 		process.WithInnerOptionBindData(cli),
@@ -146,6 +154,10 @@ func NewClientEx(inner *process.InnerOptions, copts *ClientOptions) (cli *GoClie
 		if err != nil {
 			return
 		}
+		if err = cli.handshake(); err != nil {
+			cli.conn.Close()
+			return
+		}
 	}
 
 	// async write
@@ -295,7 +307,11 @@ func (sess *GoClient) reconnLoop() (ok bool) {
 		conn, err := sess.opts.Dialer(sess.opts.Network, sess.opts.Addr)
 		if err == nil {
 			sess.conn = conn
-			break
+			if err = sess.handshake(); err == nil {
+				break
+			}
+			conn.Close()
+			sess.conn = nil
 		}
 
 		log.Error("reconnect server failed",
@@ -331,6 +347,19 @@ func (sess *GoClient) reconnLoop() (ok bool) {
 	return sess.conn != nil
 }
 
+// handshake 每次链接服务器之后重新握手,替换会话编码器
+func (sess *GoClient) handshake() error {
+	if sess.encoder == nil {
+		return nil
+	}
+	enc, err := handshake(sess.conn, sess.opts.Handshake, sess.opts.HandshakeTimeout)
+	if err != nil {
+		return err
+	}
+	sess.encoder.Store(enc)
+	return nil
+}
+
 func (sess *GoClient) ClientValid() bool {
 	return !sess.reconn.Load() && !sess.close.Load()
 }
@@ -518,6 +547,8 @@ func (p *goClientContextPool) NewContext(inner *process.InnerOptions, opts *proc
 	ctx.LoadFlag = loadFlag
 	ctx.Log = opts.Logger
 	ctx.FreeContext = ctx
+	ctx.RouterParams = nil
+	ctx.CancelTimeout = nil
 	ctx.GoClient = inner.BindData.(*GoClient)
 	return ctx
 }
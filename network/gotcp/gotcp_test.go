@@ -1,16 +1,24 @@
 package gotcp
 
 import (
+	"compress/flate"
 	"context"
 	"fmt"
+	"io"
+	"net"
 	"runtime"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/network"
 	"github.com/walleframe/walle/network/rpc"
+	"github.com/walleframe/walle/network/secure"
 	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/message"
+	"github.com/walleframe/walle/process/packet"
 	"github.com/walleframe/walle/testpkg/wpb"
 	"github.com/walleframe/walle/util"
 	"github.com/walleframe/walle/zaplog"
@@ -93,6 +101,191 @@ func TestGoTCPClient(t *testing.T) {
 	time.Sleep(time.Millisecond * 100)
 }
 
+// 服务器和客户端使用相同的压缩编码
+func TestGoTCPCompress(t *testing.T) {
+	p, err := util.GetFreePort()
+	assert.Nil(t, err, "get free port")
+	encoder := packet.NewFlateEncoder(flate.BestSpeed, 64)
+	svc := NewServer(
+		WithAddr(fmt.Sprintf(":%d", p)),
+		WithProcessOptions(process.WithPacketEncode(encoder)),
+	)
+	go svc.Run("")
+	defer svc.Shutdown(context.Background())
+	time.Sleep(time.Millisecond * 50)
+
+	cli, err := NewClient(
+		WithClientOptionAddr(fmt.Sprintf("localhost:%d", p)),
+		WithClientOptionProcessOptions(process.WithPacketEncode(encoder)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	wcli := wpb.NewWSvcClient(cli)
+
+	params := make([]int64, 1000)
+	for k := range params {
+		params[k] = 1
+	}
+	addRs, err := wcli.Add(context.Background(), &wpb.AddRq{Params: params})
+	assert.Nil(t, err, "call rpc add error")
+	if assert.NotNil(t, addRs) {
+		assert.EqualValues(t, 1000, addRs.Value, "rpc add return value")
+	}
+	// 小包不压缩
+	mulRs, err := wcli.Mul(context.Background(), &wpb.MulRq{A: 3, B: 5})
+	assert.Nil(t, err, "call rpc mul error")
+	if assert.NotNil(t, mulRs) {
+		assert.EqualValues(t, 15, mulRs.R, "rpc mul return value")
+	}
+}
+
+func TestGoTCPSecure(t *testing.T) {
+	p, err := util.GetFreePort()
+	assert.Nil(t, err, "get free port")
+	encoder := packet.NewFlateEncoder(flate.BestSpeed, 64)
+	svc := NewServer(
+		WithAddr(fmt.Sprintf(":%d", p)),
+		WithProcessOptions(process.WithPacketEncode(encoder)),
+		WithHandshake(secure.ServerHandshake()),
+	)
+	go svc.Run("")
+	defer svc.Shutdown(context.Background())
+	time.Sleep(time.Millisecond * 50)
+
+	for _, suite := range []secure.Suite{secure.SuiteAES256GCM, secure.SuiteChaCha20Poly1305} {
+		cli, err := NewClient(
+			WithClientOptionAddr(fmt.Sprintf("localhost:%d", p)),
+			WithClientOptionProcessOptions(process.WithPacketEncode(encoder)),
+			WithClientOptionHandshake(secure.ClientHandshake(suite)),
+		)
+		if err != nil {
+			t.Fatal(suite, err)
+		}
+		wcli := wpb.NewWSvcClient(cli)
+		params := make([]int64, 1000)
+		for k := range params {
+			params[k] = 1
+		}
+		addRs, err := wcli.Add(context.Background(), &wpb.AddRq{Params: params})
+		assert.Nil(t, err, "call rpc add error", suite)
+		if assert.NotNil(t, addRs) {
+			assert.EqualValues(t, 1000, addRs.Value, "rpc add return value")
+		}
+		mulRs, err := wcli.Mul(context.Background(), &wpb.MulRq{A: 3, B: 5})
+		assert.Nil(t, err, "call rpc mul error", suite)
+		if assert.NotNil(t, mulRs) {
+			assert.EqualValues(t, 15, mulRs.R, "rpc mul return value")
+		}
+		cli.Close()
+	}
+
+	// 没有握手的客户端无法建立会话
+	cli, err := NewClient(
+		WithClientOptionAddr(fmt.Sprintf("localhost:%d", p)),
+		WithClientOptionAutoReconnectTime(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	_, err = wpb.NewWSvcClient(cli).Mul(context.Background(), &wpb.MulRq{A: 3, B: 5}, rpc.WithCallOptionTimeout(time.Millisecond*200))
+	assert.NotNil(t, err, "plaintext client")
+}
+
+func TestGoTCPStream(t *testing.T) {
+	p, err := util.GetFreePort()
+	assert.Nil(t, err, "get free port")
+	type msg struct {
+		V int `json:"v"`
+	}
+	// 双向流,回复收到的消息
+	echo := func(ctx process.Context) {
+		st, err := ctx.(network.StreamCaller).AcceptStream(ctx)
+		if !assert.Nil(t, err, "accept stream") {
+			return
+		}
+		for {
+			rq := &msg{}
+			if err := st.Recv(rq); err != nil {
+				assert.Equal(t, io.EOF, err)
+				return
+			}
+			assert.Nil(t, st.Send(rq))
+		}
+	}
+	router := &process.MixRouter{}
+	router.Register("echo", echo)
+	svc := NewServer(
+		WithAddr(fmt.Sprintf(":%d", p)),
+		WithRouter(router),
+		WithProcessOptions(process.WithMsgCodec(message.JSONCodec)),
+	)
+	go svc.Run("")
+	defer svc.Shutdown(context.Background())
+	time.Sleep(time.Millisecond * 50)
+
+	cliRouter := &process.MixRouter{}
+	cliRouter.Register("echo", echo)
+	cli, err := NewClient(
+		WithClientOptionAddr(fmt.Sprintf("localhost:%d", p)),
+		WithClientOptionRouter(cliRouter),
+		WithClientOptionProcessOptions(process.WithMsgCodec(message.JSONCodec)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	check := func(link network.StreamCaller, name string) {
+		var wg sync.WaitGroup
+		for k := 0; k < 4; k++ {
+			wg.Add(1)
+			go func(k int) {
+				defer wg.Done()
+				st, err := link.NewStream(context.Background(), "echo", rpc.NewStreamOptions(rpc.WithStreamOptionWindow(2)))
+				if !assert.Nil(t, err, name) {
+					return
+				}
+				// 先发送超过窗口的消息,再读取回复
+				go func() {
+					for i := 0; i < 100; i++ {
+						assert.Nil(t, st.Send(&msg{V: k*1000 + i}), name)
+					}
+					assert.Nil(t, st.CloseSend(), name)
+				}()
+				for i := 0; i < 100; i++ {
+					rs := &msg{}
+					assert.Nil(t, st.Recv(rs), name)
+					assert.Equal(t, k*1000+i, rs.V, name)
+				}
+				assert.Equal(t, io.EOF, st.Recv(&msg{}), name)
+			}(k)
+		}
+		wg.Wait()
+	}
+	// 客户端打开流
+	check(cli.(network.StreamCaller), "client")
+	// 服务端打开流
+	var sess network.Session
+	svc.ForEach(func(s network.Session) {
+		sess = s
+	})
+	if !assert.NotNil(t, sess, "server session") {
+		return
+	}
+	check(sess.(network.StreamCaller), "session")
+
+	// 连接关闭,未结束的流返回错误
+	st, err := sess.(network.StreamCaller).NewStream(context.Background(), "echo", rpc.NewStreamOptions())
+	assert.Nil(t, err)
+	assert.Nil(t, st.Send(&msg{}))
+	assert.Nil(t, st.Recv(&msg{}))
+	sess.GetConn().(net.Conn).Close()
+	assert.Equal(t, errcode.ErrSessionClosed, st.Recv(&msg{}))
+}
+
 func BenchmarkGoTCPClient(b *testing.B) {
 	cli, err := NewClient(
 		WithClientOptionAddr(fmt.Sprintf("localhost:%d", bp)),
@@ -156,3 +349,43 @@ func BenchmarkGoTCPClient(b *testing.B) {
 	})
 
 }
+
+func TestGoTCPDeadline(t *testing.T) {
+	p, err := util.GetFreePort()
+	assert.Nil(t, err, "get free port")
+	type msg struct {
+		V int64 `json:"v"`
+	}
+	// 返回处理流程剩余时间
+	router := &process.MixRouter{}
+	router.Register("deadline", func(ctx process.Context) {
+		_, ok := ctx.(SessionContext)
+		assert.True(t, ok, "session context")
+		rs := &msg{V: -1}
+		if deadline, ok := ctx.Deadline(); ok {
+			rs.V = int64(time.Until(deadline))
+		}
+		ctx.Respond(ctx, rs, nil)
+	})
+	svc := NewServer(
+		WithAddr(fmt.Sprintf(":%d", p)),
+		WithRouter(router),
+		WithProcessOptions(process.WithMsgCodec(message.JSONCodec)),
+	)
+	go svc.Run("")
+	defer svc.Shutdown(context.Background())
+	time.Sleep(time.Millisecond * 50)
+
+	cli, err := NewClient(
+		WithClientOptionAddr(fmt.Sprintf("localhost:%d", p)),
+		WithClientOptionProcessOptions(process.WithMsgCodec(message.JSONCodec)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	rs := &msg{}
+	err = cli.Call(context.Background(), "deadline", &msg{}, rs, rpc.NewCallOptions(rpc.WithCallOptionTimeout(time.Second)))
+	assert.Nil(t, err)
+	assert.True(t, rs.V > 0 && rs.V <= int64(time.Second), "handler deadline from x-timeout: %v", time.Duration(rs.V))
+}
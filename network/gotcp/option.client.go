@@ -63,6 +63,10 @@ type ClientOptions struct {
 	MaxMessageSizeLimit int
 	// BlockConnect 创建客户端时候，是否阻塞等待链接服务器
 	BlockConnect bool
+	// Handshake 链接建立之后执行握手(例如 secure.ClientHandshake),返回会话独立的编码器. nil 不握手
+	Handshake Handshake
+	// HandshakeTimeout 握手超时时间
+	HandshakeTimeout time.Duration
 }
 
 // Network tcp/tcp4/tcp6/unix
@@ -256,6 +260,24 @@ func WithClientOptionBlockConnect(v bool) ClientOption {
 	}
 }
 
+// Handshake 链接建立之后执行握手(例如 secure.ClientHandshake),返回会话独立的编码器. nil 不握手
+func WithClientOptionHandshake(v Handshake) ClientOption {
+	return func(cc *ClientOptions) ClientOption {
+		previous := cc.Handshake
+		cc.Handshake = v
+		return WithClientOptionHandshake(previous)
+	}
+}
+
+// HandshakeTimeout 握手超时时间
+func WithClientOptionHandshakeTimeout(v time.Duration) ClientOption {
+	return func(cc *ClientOptions) ClientOption {
+		previous := cc.HandshakeTimeout
+		cc.HandshakeTimeout = v
+		return WithClientOptionHandshakeTimeout(previous)
+	}
+}
+
 // SetOption modify options
 func (cc *ClientOptions) SetOption(opt ClientOption) {
 	_ = opt(cc)
@@ -332,6 +354,8 @@ func newDefaultClientOptions() *ClientOptions {
 		ReuseReadBuffer:     true,
 		MaxMessageSizeLimit: 0,
 		BlockConnect:        true,
+		Handshake:           nil,
+		HandshakeTimeout:    time.Second * 10,
 	}
 	return cc
 }
@@ -69,6 +69,10 @@ type ServerOptions struct {
 	MaxMessageSizeLimit int
 	// Registry
 	Registry discovery.Registry
+	// Handshake 链接建立之后执行握手(例如 secure.ServerHandshake),返回会话独立的编码器. nil 不握手
+	Handshake Handshake
+	// HandshakeTimeout 握手超时时间
+	HandshakeTimeout time.Duration
 }
 
 // Addr Server Addr
@@ -281,6 +285,24 @@ func WithRegistry(v discovery.Registry) ServerOption {
 	}
 }
 
+// Handshake 链接建立之后执行握手(例如 secure.ServerHandshake),返回会话独立的编码器. nil 不握手
+func WithHandshake(v Handshake) ServerOption {
+	return func(cc *ServerOptions) ServerOption {
+		previous := cc.Handshake
+		cc.Handshake = v
+		return WithHandshake(previous)
+	}
+}
+
+// HandshakeTimeout 握手超时时间
+func WithHandshakeTimeout(v time.Duration) ServerOption {
+	return func(cc *ServerOptions) ServerOption {
+		previous := cc.HandshakeTimeout
+		cc.HandshakeTimeout = v
+		return WithHandshakeTimeout(previous)
+	}
+}
+
 // SetOption modify options
 func (cc *ServerOptions) SetOption(opt ServerOption) {
 	_ = opt(cc)
@@ -368,6 +390,8 @@ func newDefaultServerOptions() *ServerOptions {
 		ReuseReadBuffer:     false,
 		MaxMessageSizeLimit: 0,
 		Registry:            discovery.NoOpRegistry{},
+		Handshake:           nil,
+		HandshakeTimeout:    time.Second * 10,
 	}
 	return cc
 }
@@ -31,6 +31,7 @@ type (
 	Client         = network.Client
 	ClientContext  = network.ClientContext
 	WriteMethod    = network.WriteMethod
+	Handshake      = network.Handshake
 )
 
 // import const value
@@ -106,6 +107,10 @@ func walleServer() interface{} {
 		"MaxMessageSizeLimit": int(0),
 		// Registry
 		"Registry": discovery.Registry(discovery.NoOpRegistry{}),
+		// Handshake 链接建立之后执行握手(例如 secure.ServerHandshake),返回会话独立的编码器. nil 不握手
+		"Handshake": Handshake(nil),
+		// HandshakeTimeout 握手超时时间
+		"HandshakeTimeout": time.Duration(time.Second * 10),
 	}
 }
 
@@ -121,6 +126,7 @@ type GoServer struct {
 	ln         net.Listener
 	clients    map[Session]struct{}
 	stop       chan struct{}
+	serving    atomic.Bool
 }
 
 func NewServer(opts ...ServerOption) *GoServer {
@@ -193,6 +199,11 @@ func (s *GoServer) runAcceptLoop(ctx context.Context) (err error) {
 		return err
 	}
 	defer s.opts.Registry.Offline(ctx)
+	// server metrics
+	defer network.RegisterServerMetrics("gotcp", s.ln.Addr().String(), s)()
+	// listener up and registry online
+	s.serving.Store(true)
+	defer s.serving.Store(false)
 
 	for {
 		conn, err := s.ln.Accept()
@@ -233,18 +244,24 @@ func (s *GoServer) accpetConn(conn net.Conn) {
 	}()
 	// copy inner options,use for custom set bind data.
 	newInnerOptions := *s.procInner
+	// copy process options,握手之后设置会话独立的编码器
+	newProcOptions := *s.procOpts
 	// new session
 	sess := &GoSession{
 		conn: conn,
 		svr:  s,
 		RPCProcess: rpc.NewRPCProcess(
 			&newInnerOptions,
-			s.procOpts,
+			&newProcOptions,
 		),
 		ctx:    context.Background(),
 		cancel: func() {},
 	}
 	sess.opts = s.opts
+	// 加入会话列表之前创建发送队列(Broadcast,监控指标会读取)
+	if s.opts.WriteMethods == WriteAsync {
+		sess.send = make(chan []byte, s.opts.SendQueueSize)
+	}
 	sess.Process.Inner.ApplyOption(
 		process.WithInnerOptionContextPool(GoServerContextPool),
 		process.WithInnerOptionOutput(sess),
@@ -259,6 +276,18 @@ func (s *GoServer) accpetConn(conn net.Conn) {
 	}
 	// modify options
 	s.opts.NetConnOption(conn)
+	// handshake before session visible(Broadcast)
+	if s.opts.Handshake != nil {
+		enc, err := handshake(conn, s.opts.Handshake, s.opts.HandshakeTimeout)
+		if err != nil {
+			log.Warn("handshake failed", zap.Error(err), zap.Stringer("remote", conn.RemoteAddr()))
+			return
+		}
+		he := network.NewHandshakeEncoder(newProcOptions.PacketEncode)
+		he.Store(enc)
+		newProcOptions.PacketEncode = he
+		sess.encoder = enc
+	}
 	// maybe cusotm session
 	newSess, err := s.opts.NewSession(sess)
 	if err != nil {
@@ -327,7 +356,7 @@ func (s *GoServer) Broadcast(uri interface{}, msg interface{}, md metadata.MD) e
 	data = s.procOpts.PacketEncode.Encode(data)
 
 	for cli := range s.clients {
-		cli.Write(data)
+		cli.Write(network.EncodeSession(cli, data))
 	}
 	return nil
 }
@@ -359,7 +388,7 @@ func (s *GoServer) BroadcastFilter(filter func(Session) bool, uri interface{}, m
 		if filter(cli) {
 			continue
 		}
-		cli.Write(data)
+		cli.Write(network.EncodeSession(cli, data))
 	}
 	return nil
 }
@@ -375,6 +404,11 @@ func (s *GoServer) ForEach(f func(Session)) {
 	}
 }
 
+// Serving listener accepting and registry online
+func (s *GoServer) Serving() bool {
+	return s.serving.Load()
+}
+
 func (s *GoServer) Shutdown(ctx context.Context) (err error) {
 	err = s.ln.Close()
 	s.mux.Lock()
@@ -385,3 +419,12 @@ func (s *GoServer) Shutdown(ctx context.Context) (err error) {
 	s.clients = nil
 	return
 }
+
+// handshake 读写循环开始之前在链接上执行握手
+func handshake(conn net.Conn, h Handshake, timeout time.Duration) (packet.Encoder, error) {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+	return h(conn)
+}
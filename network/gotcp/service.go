@@ -2,6 +2,7 @@ package gotcp
 
 import (
 	"context"
+	"errors"
 	"net"
 
 	"github.com/walleframe/walle/app"
@@ -35,6 +36,20 @@ func (svc *GoTcpService) Stop() {
 	svc.svr.Shutdown(context.Background())
 	return
 }
+func (svc *GoTcpService) StopContext(ctx context.Context) {
+	svc.svr.Shutdown(ctx)
+	return
+}
 func (svc *GoTcpService) Finish() {
 	return
 }
+
+// Ready 服务正在接收连接. Serving 在监听成功并且注册中心上线之后才设置.
+func (svc *GoTcpService) Ready(ctx context.Context) error {
+	if !svc.svr.Serving() {
+		return ErrNotServing
+	}
+	return nil
+}
+
+var ErrNotServing = errors.New("server not serving")
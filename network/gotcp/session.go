@@ -11,6 +11,7 @@ import (
 	"github.com/walleframe/walle/network/rpc"
 	"github.com/walleframe/walle/process"
 	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
@@ -33,6 +34,8 @@ type GoSession struct {
 	//
 	writeMethod WriteMethod
 	opts        *ServerOptions
+	// 握手生成的会话编码器
+	encoder packet.Encoder
 	// close call back
 	closeChain []func(Session)
 }
@@ -97,11 +100,23 @@ func (sess *GoSession) GetServer() Server {
 	return sess.svr
 }
 
+// SessionEncoder 握手生成的会话编码器,没有握手返回nil
+func (sess *GoSession) SessionEncoder() packet.Encoder {
+	return sess.encoder
+}
+
+// SendQueueLen 异步发送队列中等待发送的消息数量
+func (sess *GoSession) SendQueueLen() int {
+	return len(sess.send)
+}
+
 // Run run client
 func (sess *GoSession) Run() {
 	// async write
 	if sess.opts.WriteMethods == WriteAsync {
-		sess.send = make(chan []byte, sess.opts.SendQueueSize)
+		if sess.send == nil {
+			sess.send = make(chan []byte, sess.opts.SendQueueSize)
+		}
 		go sess.writeLoop()
 	}
 	sess.readLoop()
@@ -238,6 +253,8 @@ func (p *goServerContextPool) NewContext(inner *process.InnerOptions, opts *proc
 	ctx.LoadFlag = loadFlag
 	ctx.Log = opts.Logger
 	ctx.FreeContext = ctx
+	ctx.RouterParams = nil
+	ctx.CancelTimeout = nil
 	ctx.GoSession = inner.BindData.(*GoSession)
 	return ctx
 }
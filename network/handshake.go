@@ -0,0 +1,78 @@
+package network
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/walleframe/walle/process/packet"
+)
+
+// Handshake 链接建立之后,读写循环开始之前在原始链接上执行(例如交换密钥).
+// 返回会话独立的网络包编码器,返回nil表示不需要额外编码.
+type Handshake func(rw io.ReadWriter) (packet.Encoder, error)
+
+// SessionEncoder 会话独立的网络包编码器(握手生成)
+type SessionEncoder interface {
+	// SessionEncoder 没有握手或者握手没有生成编码器时返回nil
+	SessionEncoder() packet.Encoder
+}
+
+// HandshakeEncoder 组合全局编码器(ProcessOptions.PacketEncode)和握手生成的会话编码器.
+// 编码时先执行全局编码器,解码顺序相反. 客户端重连之后重新握手,使用 Store 替换会话编码器.
+type HandshakeEncoder struct {
+	global packet.Encoder
+	sess   atomic.Value
+}
+
+type encoderHolder struct {
+	packet.Encoder
+}
+
+var _ packet.Encoder = (*HandshakeEncoder)(nil)
+
+// NewHandshakeEncoder 创建组合编码器, global 为nil时只使用会话编码器
+func NewHandshakeEncoder(global packet.Encoder) *HandshakeEncoder {
+	if global == nil {
+		global = packet.EmtpyPacketEncoder
+	}
+	return &HandshakeEncoder{global: global}
+}
+
+// Store 替换会话编码器
+func (e *HandshakeEncoder) Store(enc packet.Encoder) {
+	e.sess.Store(encoderHolder{enc})
+}
+
+// SessionEncoder 当前会话编码器
+func (e *HandshakeEncoder) SessionEncoder() packet.Encoder {
+	h, _ := e.sess.Load().(encoderHolder)
+	return h.Encoder
+}
+
+func (e *HandshakeEncoder) Encode(buf []byte) []byte {
+	buf = e.global.Encode(buf)
+	if enc := e.SessionEncoder(); enc != nil && buf != nil {
+		buf = enc.Encode(buf)
+	}
+	return buf
+}
+
+func (e *HandshakeEncoder) Decode(buf []byte) []byte {
+	if enc := e.SessionEncoder(); enc != nil {
+		buf = enc.Decode(buf)
+		if buf == nil {
+			return nil
+		}
+	}
+	return e.global.Decode(buf)
+}
+
+// EncodeSession 广播消息已经使用全局编码器编码,发送之前需要再使用会话编码器编码
+func EncodeSession(sess Session, data []byte) []byte {
+	if se, ok := sess.(SessionEncoder); ok {
+		if enc := se.SessionEncoder(); enc != nil {
+			return enc.Encode(data)
+		}
+	}
+	return data
+}
@@ -0,0 +1,44 @@
+package network
+
+import (
+	"github.com/walleframe/walle/util/metrics"
+)
+
+// 服务器监控指标
+var (
+	metricSessions = metrics.NewGauge("walle_network_sessions",
+		"connected sessions", "network", "addr")
+	metricSendQueue = metrics.NewGauge("walle_network_send_queue_depth",
+		"messages waiting in session async send queues", "network", "addr")
+)
+
+// SendQueuer 会话异步发送队列
+type SendQueuer interface {
+	// SendQueueLen 等待发送的消息数量
+	SendQueueLen() int
+}
+
+// RegisterServerMetrics 注册服务器监控指标(会话数量,发送队列长度),采集时遍历会话统计.
+// 服务器停止之后调用返回的函数删除指标.
+func RegisterServerMetrics(network, addr string, svr Server) (unregister func()) {
+	metricSessions.With(network, addr).SetFunc(func() float64 {
+		count := 0
+		svr.ForEach(func(Session) {
+			count++
+		})
+		return float64(count)
+	})
+	metricSendQueue.With(network, addr).SetFunc(func() float64 {
+		count := 0
+		svr.ForEach(func(sess Session) {
+			if q, ok := sess.(SendQueuer); ok {
+				count += q.SendQueueLen()
+			}
+		})
+		return float64(count)
+	})
+	return func() {
+		metricSessions.Delete(network, addr)
+		metricSendQueue.Delete(network, addr)
+	}
+}
@@ -17,6 +17,12 @@ type Caller interface {
 	Notify(ctx context.Context, uri interface{}, rq interface{}, opts *rpc.NoticeOptions) (err error)
 }
 
+// StreamCaller 可选接口. 流式rpc, gotcp/ws/gnet 的会话和客户端都实现此接口, 使用类型断言获取.
+type StreamCaller interface {
+	NewStream(ctx context.Context, uri interface{}, opts *rpc.StreamOptions) (st rpc.Stream, err error)
+	AcceptStream(ctx process.Context) (st rpc.Stream, err error)
+}
+
 type CallerResponser interface {
 	Write(ctx context.Context, payload interface{}, md metadata.MD)
 }
@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/util/metrics"
+)
+
+// rpc请求监控指标
+var (
+	metricClientRequests = metrics.NewCounter("walle_rpc_client_requests_total",
+		"rpc client requests", "route", "type")
+	metricClientErrors = metrics.NewCounter("walle_rpc_client_errors_total",
+		"rpc client requests failed", "route", "type", "code")
+	metricClientDuration = metrics.NewHistogram("walle_rpc_client_duration_seconds",
+		"rpc client request latency(until response or timeout)", nil, "route", "type")
+)
+
+// callStat 记录一次请求的监控指标
+type callStat struct {
+	route string
+	typ   string
+	start time.Time
+}
+
+// newCallStat 开始记录请求. 未开启监控(ProcessOptions.Metrics)时返回nil
+func (p *RPCProcess) newCallStat(uri interface{}, typ string) *callStat {
+	if !p.Opts.Metrics {
+		return nil
+	}
+	stat := &callStat{
+		route: spanName(uri),
+		typ:   typ,
+		start: time.Now(),
+	}
+	metricClientRequests.With(stat.route, stat.typ).Inc()
+	return stat
+}
+
+func (stat *callStat) finish(err error) {
+	if stat == nil {
+		return
+	}
+	metricClientDuration.With(stat.route, stat.typ).ObserveDuration(time.Since(stat.start))
+	if err != nil {
+		metricClientErrors.With(stat.route, stat.typ, strconv.FormatUint(uint64(errcode.Code(err)), 10)).Inc()
+	}
+}
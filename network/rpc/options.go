@@ -52,3 +52,17 @@ func walleNoticeCallOption() interface{} {
 		"Metadata": metadata.MD(nil),
 	}
 }
+
+// StreamOption stream rpc
+//
+//go:generate gogen option -n StreamOption -f Stream -o option.stream.go
+func walleStreamOption() interface{} {
+	return map[string]interface{}{
+		// stream timeout, 0 means no limit
+		"Timeout": time.Duration(0),
+		// metadata
+		"Metadata": metadata.MD(nil),
+		// receive window, max buffered messages not read by Recv
+		"Window": uint32(64),
+	}
+}
@@ -2,11 +2,15 @@ package rpc
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/walleframe/walle/process"
 	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/metadata"
 	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/process/tracing"
 	"github.com/walleframe/walle/zaplog"
 	"go.uber.org/zap"
 )
@@ -32,6 +36,10 @@ type RPCProcess struct {
 	// TODO:待优化, 使用多个锁
 	mux        sync.Mutex
 	sessionMap map[uint64]*rpcSession
+	// stream rpc
+	smux     sync.Mutex
+	streams  map[streamKey]*stream
+	accepted int // 对端打开的流数量
 }
 
 func NewRPCProcess(inner *process.InnerOptions, opts *process.ProcessOptions) *RPCProcess {
@@ -52,6 +60,11 @@ func (p *RPCProcess) OnReply(in interface{}) (filter bool) {
 	if !ok {
 		return
 	}
+	// 流式rpc
+	if rsp.Cmd() == packet.CmdStream || rsp.Cmd() == packet.CmdStreamReply {
+		p.onStream(rsp)
+		return true
+	}
 	// rpc 请求回包
 	if rsp.Cmd() != packet.CmdResponse {
 		return
@@ -88,9 +101,23 @@ func (p *RPCProcess) Call(ctx context.Context, uri interface{}, rq, rs interface
 		return
 	}
 
+	timeout, ok := requestTimeout(ctx, opts.Timeout)
+	if !ok {
+		err = errcode.ErrTimeout
+		log.Warn("request deadline exceeded before send", zap.Any("uri", uri))
+		return
+	}
+
+	stat := p.newCallStat(uri, "call")
+	span := tracing.StartSpan(ctx, spanName(uri), tracing.SpanKindClient)
+	defer func() {
+		span.Finish(err)
+		stat.finish(err)
+	}()
+
 	req := p.Opts.PacketPool.Get().(*packet.Packet)
 	defer p.Opts.PacketPool.Put(req)
-	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdRequest, uri, opts.Metadata)
+	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdRequest, uri, tracing.Inject(timeoutMD(opts.Metadata, timeout), span))
 	if err != nil {
 		log.Error("new packet failed", zap.Error(err), zap.Any("reqeust", rq), zap.Object("packet", req))
 		return
@@ -107,7 +134,7 @@ func (p *RPCProcess) Call(ctx context.Context, uri interface{}, rq, rs interface
 		return
 	}
 
-	data = p.Opts.PacketEncode.Decode(data)
+	data = p.Opts.PacketEncode.Encode(data)
 
 	session := &rpcSession{
 		seq:  req.SessionID(),
@@ -158,8 +185,23 @@ func (p *RPCProcess) AsyncCall(ctx context.Context, uri interface{}, rq interfac
 		log.Error("unexcepted code: not set Output(io.Writer)", zap.Any("uri", uri))
 		return
 	}
+	timeout, ok := requestTimeout(ctx, opts.Timeout)
+	if !ok {
+		err = errcode.ErrTimeout
+		log.Warn("request deadline exceeded before send", zap.Any("uri", uri))
+		return
+	}
+	// 异步请求在收到回复或者超时时结束span和记录耗时
+	stat := p.newCallStat(uri, "async")
+	span := tracing.StartSpan(ctx, spanName(uri), tracing.SpanKindClient)
+	defer func() {
+		if err != nil {
+			span.Finish(err)
+			stat.finish(err)
+		}
+	}()
 	req := p.Opts.PacketPool.Get().(*packet.Packet)
-	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdRequest, uri, opts.Metadata)
+	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdRequest, uri, tracing.Inject(timeoutMD(opts.Metadata, timeout), span))
 	if err != nil {
 		log.Error("new packet failed", zap.Error(err), zap.Any("reqeust", rq), zap.Object("packet", req))
 		return
@@ -176,7 +218,7 @@ func (p *RPCProcess) AsyncCall(ctx context.Context, uri interface{}, rq interfac
 		return
 	}
 
-	data = p.Opts.PacketEncode.Decode(data)
+	data = p.Opts.PacketEncode.Encode(data)
 
 	session := &rpcSession{
 		seq: req.SessionID(),
@@ -187,22 +229,34 @@ func (p *RPCProcess) AsyncCall(ctx context.Context, uri interface{}, rq interfac
 	// session.async[len(session.async)-1] = af
 	// }
 	session.aFunc = append(session.aFunc, af)
-	session.aFilter = opts.ResponseFilter
+	session.aFilter = func(ctx process.Context, req, rsp interface{}) {
+		rspErr := p.Opts.PacketWraper.PayloadUnmarshal(rsp, p.Opts.MsgCodec, nil)
+		span.Finish(rspErr)
+		stat.finish(rspErr)
+		opts.ResponseFilter(ctx, req, rsp)
+	}
 	session.aReq = req
 	p.saveSession(req.SessionID(), session)
 	sessionID := req.SessionID()
 
-	// timeout options
+	// timeout options. 剩余时间已经包含ctx的截止时间.
+	// 处理函数的Context处理流程结束之后会被回收,只继承截止时间,其他ctx取消时结束等待.
 	var cancel func()
-	if opts.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	if timeout > 0 {
+		parent := ctx
+		if _, ok := ctx.(process.Context); ok {
+			parent = context.Background()
+		}
+		ctx, cancel = context.WithTimeout(parent, timeout)
 		// with timeout need use response chan
 		session.done = make(chan *packet.Packet, 1)
 	}
 	// send request
 	_, err = p.Inner.Output.Write(data)
 	if err != nil {
-		cancel()
+		if cancel != nil {
+			cancel()
+		}
 		p.delSession(req.SessionID())
 		p.Opts.PacketPool.Put(req)
 		log.Error("write data failed", zap.Error(err), zap.Any("reqeust", rq), zap.Object("packet", req))
@@ -265,8 +319,14 @@ func (p *RPCProcess) Notify(ctx context.Context, uri interface{}, rq interface{}
 		log.Error("unexcepted code: not set Output(io.Writer)", zap.Any("uri", uri))
 		return
 	}
+	stat := p.newCallStat(uri, "notify")
+	span := tracing.StartSpan(ctx, spanName(uri), tracing.SpanKindProducer)
+	defer func() {
+		span.Finish(err)
+		stat.finish(err)
+	}()
 	req := p.Opts.PacketPool.Get().(*packet.Packet)
-	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdRequest, uri, opts.Metadata)
+	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdRequest, uri, tracing.Inject(opts.Metadata, span))
 	if err != nil {
 		log.Error("new packet failed", zap.Error(err), zap.Any("reqeust", rq), zap.Object("packet", req))
 		return
@@ -283,7 +343,7 @@ func (p *RPCProcess) Notify(ctx context.Context, uri interface{}, rq interface{}
 		return
 	}
 
-	data = p.Opts.PacketEncode.Decode(data)
+	data = p.Opts.PacketEncode.Encode(data)
 
 	// timeout options
 	if opts.Timeout > 0 {
@@ -303,6 +363,7 @@ func (p *RPCProcess) Notify(ctx context.Context, uri interface{}, rq interface{}
 
 // Clean session 清理（rpc请求等缓存清理）
 func (p *RPCProcess) Clean() {
+	p.cleanStreams()
 	p.mux.Lock()
 	defer p.mux.Unlock()
 	if p.sessionMap == nil {
@@ -365,3 +426,35 @@ func (p *RPCProcess) saveSession(id uint64, sess *rpcSession) {
 	p.mux.Unlock()
 	return
 }
+
+// requestTimeout 请求等待时间,取ctx剩余时间和timeout的较小值. 0表示不限制, ok为false表示已经超时.
+func requestTimeout(ctx context.Context, timeout time.Duration) (_ time.Duration, ok bool) {
+	deadline, has := ctx.Deadline()
+	if !has {
+		return timeout, true
+	}
+	remain := time.Until(deadline)
+	if remain <= 0 {
+		return 0, false
+	}
+	if timeout <= 0 || remain < timeout {
+		timeout = remain
+	}
+	return timeout, true
+}
+
+// timeoutMD 请求metadata附加等待时间,被调用方使用相同的截止时间
+func timeoutMD(md metadata.MD, timeout time.Duration) metadata.MD {
+	if timeout <= 0 {
+		return md
+	}
+	return metadata.WithTimeout(md, timeout)
+}
+
+// spanName 链路追踪span名称,消息ID使用 "msgid:{id}"
+func spanName(uri interface{}) string {
+	if v, ok := uri.(string); ok {
+		return v
+	}
+	return fmt.Sprintf("msgid:%v", uri)
+}
@@ -9,10 +9,13 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
 	"github.com/walleframe/walle/process/message"
 	metadata "github.com/walleframe/walle/process/metadata"
 	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/process/tracing"
 	"github.com/walleframe/walle/testpkg"
+	"github.com/walleframe/walle/testpkg/mock_packet"
 	"github.com/walleframe/walle/zaplog"
 	"go.uber.org/zap"
 )
@@ -97,6 +100,13 @@ func TestProcess_Call(t *testing.T) {
 		real := packet.NewPacket()
 		err = packet.GetCodec().Unmarshal(buf.Bytes(), real)
 		assert.Nil(t, err, "unmarshal data error")
+		// 剩余时间写入metadata
+		md, err := packet.GetProtocolWraper().GetMetadata(real)
+		assert.Nil(t, err, "get metadata")
+		timeout, ok := md.Timeout()
+		assert.True(t, ok, "timeout metadata")
+		assert.True(t, timeout > 0 && timeout <= time.Second, "timeout value")
+		delete(md, metadata.TimeoutKey)
 		rq.CleanForTest()
 		real.CleanForTest()
 		assert.EqualValues(t, rq, real, "final data")
@@ -198,6 +208,57 @@ func TestProcess_AsyncCall(t *testing.T) {
 	//wg.Wait()
 }
 
+// 调用方取消ctx时结束等待
+func TestProcess_AsyncCallCancel(t *testing.T) {
+	p := NewRPCProcess(
+		process.NewInnerOptions(
+			process.WithInnerOptionOutput(&bytes.Buffer{}),
+		),
+		process.NewProcessOptions(
+			process.WithLogger(zaplog.NewLogger(zap.NewNop())),
+			process.WithMsgCodec(message.JSONCodec),
+		),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	err := p.AsyncCall(ctx, "kk", &struct{}{},
+		func(ctx process.Context) {
+			done <- ctx.Bind(&struct{}{})
+		},
+		NewAsyncCallOptions(WithAsyncCallOptionTimeout(time.Minute)),
+	)
+	assert.Nil(t, err, "async call")
+	cancel()
+	select {
+	case err = <-done:
+		assert.True(t, errcode.Is(err, errcode.ErrorCodeTimeout), "timeout response")
+	case <-time.After(time.Second):
+		t.Fatal("async call not aborted by ctx cancel")
+	}
+}
+
+// 发送请求使用 Encode 编码(不是 Decode)
+func TestProcess_Encode(t *testing.T) {
+	mc := gomock.NewController(t)
+	enc := mock_packet.NewMockEncoder(mc)
+	enc.EXPECT().Encode(gomock.Any()).DoAndReturn(func(buf []byte) []byte { return buf }).Times(3)
+	p := NewRPCProcess(
+		process.NewInnerOptions(
+			process.WithInnerOptionOutput(&bytes.Buffer{}),
+		),
+		process.NewProcessOptions(
+			process.WithLogger(zaplog.NewLogger(zap.NewNop())),
+			process.WithMsgCodec(message.JSONCodec),
+			process.WithPacketEncode(enc),
+		),
+	)
+	ctx := context.Background()
+	assert.Nil(t, p.Notify(ctx, "kk", &struct{}{}, NewNoticeOptions()))
+	assert.Nil(t, p.AsyncCall(ctx, "kk", &struct{}{}, func(ctx process.Context) {}, NewAsyncCallOptions()))
+	err := p.Call(ctx, "kk", &struct{}{}, &struct{}{}, NewCallOptions(WithCallOptionTimeout(time.Millisecond)))
+	assert.Equal(t, errcode.ErrTimeout, err)
+}
+
 func TestProcess_Notify(t *testing.T) {
 	packet.SetPacketWraper(packet.NewPacketWraper())
 	type testJsonST struct {
@@ -242,6 +303,46 @@ func TestProcess_Notify(t *testing.T) {
 	assert.EqualValues(t, rq, real, "final data")
 }
 
+// 通知请求传递链路信息
+func TestProcess_NotifyTracing(t *testing.T) {
+	packet.SetPacketWraper(packet.NewPacketWraper())
+	exp := tracing.NewMemoryExporter()
+	tracing.SetExporter(exp)
+	defer tracing.SetExporter(nil)
+
+	buf := &bytes.Buffer{}
+	p := NewRPCProcess(
+		process.NewInnerOptions(
+			process.WithInnerOptionOutput(buf),
+		),
+		process.NewProcessOptions(
+			process.WithLogger(
+				zaplog.NewLogger(zap.NewNop()),
+			),
+			process.WithMsgCodec(message.JSONCodec),
+		),
+	)
+	parent := tracing.NewSpan(tracing.SpanContext{}, "handler", tracing.SpanKindServer)
+	ctx := tracing.ContextWithSpan(context.Background(), parent)
+	err := p.Notify(ctx, uint32(10), struct{}{}, NewNoticeOptions())
+	assert.Nil(t, err, "notify error")
+
+	real := packet.NewPacket()
+	err = packet.GetCodec().Unmarshal(buf.Bytes(), real)
+	assert.Nil(t, err, "unmarshal data error")
+	sc, ok := tracing.Extract(real.GetMD())
+	assert.True(t, ok, "traceparent")
+
+	spans := exp.Spans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "msgid:10", spans[0].Name)
+		assert.Equal(t, tracing.SpanKindProducer, spans[0].Kind)
+		assert.Equal(t, parent.TraceID, spans[0].TraceID)
+		assert.Equal(t, parent.SpanID, spans[0].ParentID)
+		assert.Equal(t, spans[0].SpanContext(), sc)
+	}
+}
+
 func TestProcess_WithRouter(t *testing.T) {
 	type testJsonST struct {
 		V int `json:"v"`
@@ -0,0 +1,506 @@
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/process/tracing"
+	"go.uber.org/zap"
+)
+
+// Stream 流式rpc. 同一会话的多个流使用流ID(packet sessionID)复用连接.
+// Send 和 Recv 可以在不同协程同时调用.
+type Stream interface {
+	// Context 流的上下文,流结束之后取消
+	Context() context.Context
+	// Metadata 打开流时携带的metadata
+	Metadata() metadata.MD
+	// Send 发送消息. 对端接收窗口已满时等待. 流已经正常结束返回 io.EOF
+	Send(msg interface{}) error
+	// Recv 接收消息. 对端半关闭或者流正常结束返回 io.EOF,出错结束返回对端错误
+	Recv(msg interface{}) error
+	// CloseSend 半关闭,通知对端不再发送消息. 双方都半关闭之后流结束
+	CloseSend() error
+	// Close 结束流. err为nil正常结束,否则对端收到err. 流已经结束时不做处理
+	Close(err error) error
+}
+
+// DefaultStreamWindow 接受方的接收窗口(未读取的最大消息数)
+var DefaultStreamWindow uint32 = 64
+
+// MaxConcurrentStreams 每个会话对端同时打开的最大流数量,超过时直接结束新打开的流(errcode.ErrTooManyRequests). 0表示不限制
+var MaxConcurrentStreams = 128
+
+// streamKey 双方打开的流ID可能相同,使用打开方区分
+type streamKey struct {
+	id     uint64
+	opener bool
+}
+
+type stream struct {
+	p      *RPCProcess
+	key    streamKey
+	cmd    packet.PacketCmd
+	md     metadata.MD
+	window uint32
+	ctx    context.Context
+	cancel context.CancelFunc
+	// 流结束时回调(监控,链路追踪)
+	onDone func(err error)
+
+	mux      sync.Mutex
+	queue    []*packet.Packet
+	consumed uint32 // 已读取未确认的消息数
+	credit   uint32 // 发送窗口
+	sendDone bool   // 本端已经半关闭
+	recvErr  error  // 读取完消息之后返回的错误
+	err      error  // 流结束原因
+	readable chan struct{}
+	writable chan struct{}
+	done     chan struct{}
+}
+
+func (p *RPCProcess) newStream(ctx context.Context, key streamKey, md metadata.MD, window uint32, timeout time.Duration) *stream {
+	s := &stream{
+		p:        p,
+		key:      key,
+		cmd:      packet.CmdStreamReply,
+		md:       md,
+		window:   window,
+		readable: make(chan struct{}, 1),
+		writable: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	if key.opener {
+		s.cmd = packet.CmdStream
+	}
+	if timeout > 0 {
+		s.ctx, s.cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		s.ctx, s.cancel = context.WithCancel(ctx)
+	}
+	return s
+}
+
+// watch 上下文取消或者超时,通知对端结束流
+func (s *stream) watch() {
+	select {
+	case <-s.done:
+	case <-s.ctx.Done():
+		err := errcode.ErrCanceled
+		if s.ctx.Err() == context.DeadlineExceeded {
+			err = errcode.ErrTimeout
+		}
+		if s.terminate(err) {
+			s.write(packet.FlagStreamReset, err)
+		}
+	}
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stream) Metadata() metadata.MD {
+	return s.md
+}
+
+func (s *stream) Send(msg interface{}) (err error) {
+	for {
+		s.mux.Lock()
+		if s.err != nil {
+			err = s.err
+		} else if s.sendDone {
+			err = errcode.ErrStreamClosed
+		} else if s.credit > 0 {
+			s.credit--
+			s.mux.Unlock()
+			return s.write(0, msg)
+		}
+		s.mux.Unlock()
+		if err != nil {
+			return
+		}
+		select {
+		case <-s.writable:
+		case <-s.done:
+		}
+	}
+}
+
+func (s *stream) Recv(msg interface{}) (err error) {
+	for {
+		s.mux.Lock()
+		if len(s.queue) > 0 {
+			pkg := s.queue[0]
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+			var credit uint32
+			if s.err == nil {
+				s.consumed++
+				if s.consumed >= (s.window+1)/2 {
+					credit, s.consumed = s.consumed, 0
+				}
+			}
+			s.mux.Unlock()
+			if credit > 0 {
+				s.write(packet.FlagStreamWindow, credit)
+			}
+			err = s.p.Opts.PacketWraper.PayloadUnmarshal(pkg, s.p.Opts.MsgCodec, msg)
+			s.p.Opts.PacketPool.Put(pkg)
+			return
+		}
+		err = s.recvErr
+		s.mux.Unlock()
+		if err != nil {
+			return
+		}
+		select {
+		case <-s.readable:
+		case <-s.done:
+		}
+	}
+}
+
+func (s *stream) CloseSend() (err error) {
+	s.mux.Lock()
+	if s.err != nil || s.sendDone {
+		s.mux.Unlock()
+		return
+	}
+	s.sendDone = true
+	finished := s.recvErr == io.EOF
+	s.mux.Unlock()
+	err = s.write(packet.FlagStreamEnd, nil)
+	if finished {
+		s.terminate(io.EOF)
+	}
+	return
+}
+
+func (s *stream) Close(err error) error {
+	// 本端之后的读写返回 ErrStreamClosed, 监控和链路追踪记录err
+	if !s.finish(errcode.ErrStreamClosed, err) {
+		return nil
+	}
+	s.mux.Lock()
+	for _, pkg := range s.queue {
+		s.p.Opts.PacketPool.Put(pkg)
+	}
+	s.queue = nil
+	s.mux.Unlock()
+	return s.write(packet.FlagStreamReset, err)
+}
+
+// terminate 结束流. err 为io.EOF表示正常结束. 流已经结束返回false
+func (s *stream) terminate(err error) bool {
+	return s.finish(err, err)
+}
+
+// finish 结束流. err 为本端读写返回的错误, result 为流的结果(io.EOF表示正常结束)
+func (s *stream) finish(err, result error) bool {
+	s.mux.Lock()
+	if s.err != nil {
+		s.mux.Unlock()
+		return false
+	}
+	s.err = err
+	if err != io.EOF || s.recvErr == nil {
+		s.recvErr = err
+	}
+	close(s.done)
+	s.mux.Unlock()
+	s.p.delStream(s.key)
+	s.cancel()
+	if s.onDone != nil {
+		if result == io.EOF {
+			result = nil
+		}
+		s.onDone(result)
+	}
+	return true
+}
+
+// onFrame 处理对端发送的流消息,在读协程调用
+func (s *stream) onFrame(pkg *packet.Packet) {
+	switch {
+	case pkg.HasFlag(packet.FlagStreamReset):
+		err := s.p.Opts.PacketWraper.PayloadUnmarshal(pkg, s.p.Opts.MsgCodec, nil)
+		if err == nil {
+			err = io.EOF
+		}
+		s.p.Opts.PacketPool.Put(pkg)
+		s.terminate(err)
+	case pkg.HasFlag(packet.FlagStreamWindow):
+		payload := pkg.Payload()
+		if len(payload) != 4 {
+			s.p.Opts.PacketPool.Put(pkg)
+			s.abort(errcode.ErrInvalidPacket)
+			return
+		}
+		s.mux.Lock()
+		s.credit += binary.BigEndian.Uint32(payload)
+		s.mux.Unlock()
+		s.p.Opts.PacketPool.Put(pkg)
+		notify(s.writable)
+	case pkg.HasFlag(packet.FlagStreamEnd):
+		s.p.Opts.PacketPool.Put(pkg)
+		s.mux.Lock()
+		if s.recvErr == nil {
+			s.recvErr = io.EOF
+		}
+		finished := s.sendDone
+		s.mux.Unlock()
+		notify(s.readable)
+		if finished {
+			s.terminate(io.EOF)
+		}
+	default:
+		s.mux.Lock()
+		if s.recvErr != nil {
+			// 已经半关闭
+			s.mux.Unlock()
+			s.p.Opts.PacketPool.Put(pkg)
+			s.abort(errcode.ErrInvalidPacket)
+			return
+		}
+		if uint32(len(s.queue)) >= s.window {
+			// 超出接收窗口
+			s.mux.Unlock()
+			s.p.Opts.PacketPool.Put(pkg)
+			s.abort(errcode.ErrInvalidPacket)
+			return
+		}
+		s.queue = append(s.queue, pkg)
+		s.mux.Unlock()
+		notify(s.readable)
+	}
+}
+
+// abort 对端违反协议,结束流并通知对端
+func (s *stream) abort(err error) {
+	s.p.logger("rpcstream.abort").Warn("stream protocol error", zap.Uint64("stream", s.key.id), zap.Error(err))
+	if s.terminate(err) {
+		s.write(packet.FlagStreamReset, err)
+	}
+}
+
+// write 发送流消息. FlagStreamWindow 的body是接收窗口增量
+func (s *stream) write(flag packet.PacketFlag, body interface{}) (err error) {
+	p := s.p
+	pkg := p.Opts.PacketPool.Get().(*packet.Packet)
+	defer p.Opts.PacketPool.Put(pkg)
+	pkg.SetCmd(s.cmd)
+	pkg.SetSeesonID(s.key.id)
+	if flag != 0 {
+		pkg.SetFlag(flag, true)
+	}
+	if credit, ok := body.(uint32); ok && flag == packet.FlagStreamWindow {
+		pkg.SetPayload(windowPayload(credit))
+	} else if body != nil {
+		err = p.Opts.PacketWraper.PayloadMarshal(pkg, p.Opts.MsgCodec, body)
+		if err != nil {
+			p.logger("rpcstream.write").Error("marshal payload failed", zap.Error(err), zap.Any("body", body))
+			return
+		}
+	}
+	return p.writePacket(pkg)
+}
+
+func (p *RPCProcess) writePacket(pkg *packet.Packet) (err error) {
+	if p.Inner.Output == nil {
+		return errcode.ErrUnexpectedCode
+	}
+	data, err := p.Opts.PacketCodec.Marshal(pkg)
+	if err != nil {
+		p.logger("rpcprocess.writePacket").Error("marshal packet failed", zap.Error(err), zap.Object("packet", pkg))
+		return
+	}
+	data = p.Opts.PacketEncode.Encode(data)
+	_, err = p.Inner.Output.Write(data)
+	return
+}
+
+func windowPayload(credit uint32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, credit)
+	return buf
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// NewStream 打开流式rpc. 流结束之前ctx取消或者超时,通知对端结束流.
+func (p *RPCProcess) NewStream(ctx context.Context, uri interface{}, opts *StreamOptions) (st Stream, err error) {
+	log := p.logger("process.NewStream")
+	if p.Inner.Output == nil {
+		err = errcode.ErrUnexpectedCode
+		log.Error("unexcepted code: not set Output(io.Writer)", zap.Any("uri", uri))
+		return
+	}
+	timeout, ok := requestTimeout(ctx, opts.Timeout)
+	if !ok {
+		err = errcode.ErrTimeout
+		log.Warn("request deadline exceeded before send", zap.Any("uri", uri))
+		return
+	}
+	if opts.Window == 0 {
+		err = errcode.ErrUnexpectedCode
+		log.Error("unexcepted code: stream window is zero", zap.Any("uri", uri))
+		return
+	}
+	stat := p.newCallStat(uri, "stream")
+	span := tracing.StartSpan(ctx, spanName(uri), tracing.SpanKindClient)
+
+	req := p.Opts.PacketPool.Get().(*packet.Packet)
+	defer p.Opts.PacketPool.Put(req)
+	err = p.Opts.PacketWraper.NewPacket(req, packet.CmdStream, uri, tracing.Inject(timeoutMD(opts.Metadata, timeout), span))
+	if err != nil {
+		log.Error("new packet failed", zap.Error(err), zap.Object("packet", req))
+		span.Finish(err)
+		stat.finish(err)
+		return
+	}
+	req.SetFlag(packet.FlagStreamOpen, true)
+	req.SetPayload(windowPayload(opts.Window))
+
+	s := p.newStream(ctx, streamKey{id: req.SessionID(), opener: true}, opts.Metadata, opts.Window, timeout)
+	s.onDone = func(err error) {
+		span.Finish(err)
+		stat.finish(err)
+	}
+	p.saveStream(s)
+	err = p.writePacket(req)
+	if err != nil {
+		log.Error("write data failed", zap.Error(err), zap.Object("packet", req))
+		s.terminate(err)
+		return
+	}
+	go s.watch()
+	return s, nil
+}
+
+// AcceptStream 获取请求对应的流. 处理函数返回之后流自动结束(Close(nil)).
+func (p *RPCProcess) AcceptStream(ctx process.Context) (st Stream, err error) {
+	pkg, ok := ctx.GetRequestPacket().(*packet.Packet)
+	if !ok || pkg.Cmd() != packet.CmdStream || !pkg.HasFlag(packet.FlagStreamOpen) {
+		return nil, errcode.ErrNotSupport
+	}
+	s := p.getStream(streamKey{id: pkg.SessionID()})
+	if s == nil {
+		return nil, errcode.ErrStreamClosed
+	}
+	return s, nil
+}
+
+// onStream 处理流消息. 打开流的消息在新的协程路由处理
+func (p *RPCProcess) onStream(pkg *packet.Packet) {
+	log := p.logger("rpcprocess.onStream")
+	key := streamKey{id: pkg.SessionID(), opener: pkg.Cmd() == packet.CmdStreamReply}
+	if !key.opener && pkg.HasFlag(packet.FlagStreamOpen) {
+		p.acceptStream(pkg)
+		return
+	}
+	s := p.getStream(key)
+	if s == nil {
+		// 流已经结束
+		log.Debug("stream not found", zap.Object("pkg", pkg))
+		p.Opts.PacketPool.Put(pkg)
+		return
+	}
+	s.onFrame(pkg)
+}
+
+func (p *RPCProcess) acceptStream(pkg *packet.Packet) {
+	log := p.logger("rpcprocess.acceptStream")
+	key := streamKey{id: pkg.SessionID()}
+	payload := pkg.Payload()
+	if len(payload) != 4 || binary.BigEndian.Uint32(payload) == 0 || p.getStream(key) != nil {
+		log.Warn("invalid stream open packet", zap.Object("pkg", pkg))
+		p.Opts.PacketPool.Put(pkg)
+		return
+	}
+	if MaxConcurrentStreams > 0 && p.acceptedStreams() >= MaxConcurrentStreams {
+		log.Warn("too many concurrent streams", zap.Int("max", MaxConcurrentStreams), zap.Object("pkg", pkg))
+		reset := &stream{p: p, key: key, cmd: packet.CmdStreamReply}
+		reset.write(packet.FlagStreamReset, errcode.ErrTooManyRequests)
+		p.Opts.PacketPool.Put(pkg)
+		return
+	}
+	md := pkg.GetMD()
+	timeout, _ := md.Timeout()
+	s := p.newStream(context.Background(), key, md, DefaultStreamWindow, timeout)
+	s.credit = binary.BigEndian.Uint32(payload)
+	pkg.SetPayload(nil)
+	p.saveStream(s)
+	// 接收窗口,同时通知打开方流已经建立
+	if err := s.write(packet.FlagStreamWindow, s.window); err != nil {
+		log.Error("write stream window failed", zap.Error(err), zap.Object("pkg", pkg))
+		s.terminate(err)
+		p.Opts.PacketPool.Put(pkg)
+		return
+	}
+	go s.watch()
+	// 处理函数返回之后结束流, 错误发送给对端
+	p.DispatchPacket(pkg, func(err error) {
+		s.Close(err)
+	})
+}
+
+func (p *RPCProcess) getStream(key streamKey) *stream {
+	p.smux.Lock()
+	defer p.smux.Unlock()
+	return p.streams[key]
+}
+
+func (p *RPCProcess) saveStream(s *stream) {
+	p.smux.Lock()
+	if p.streams == nil {
+		p.streams = make(map[streamKey]*stream)
+	}
+	if _, ok := p.streams[s.key]; !ok && !s.key.opener {
+		p.accepted++
+	}
+	p.streams[s.key] = s
+	p.smux.Unlock()
+}
+
+func (p *RPCProcess) delStream(key streamKey) {
+	p.smux.Lock()
+	if _, ok := p.streams[key]; ok && !key.opener {
+		p.accepted--
+	}
+	delete(p.streams, key)
+	p.smux.Unlock()
+}
+
+// acceptedStreams 对端打开的流数量
+func (p *RPCProcess) acceptedStreams() int {
+	p.smux.Lock()
+	defer p.smux.Unlock()
+	return p.accepted
+}
+
+// cleanStreams 会话关闭,结束所有流
+func (p *RPCProcess) cleanStreams() {
+	p.smux.Lock()
+	streams := make([]*stream, 0, len(p.streams))
+	for _, s := range p.streams {
+		streams = append(streams, s)
+	}
+	p.smux.Unlock()
+	for _, s := range streams {
+		s.terminate(errcode.ErrSessionClosed)
+	}
+}
@@ -0,0 +1,291 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/message"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+type chanWriter chan []byte
+
+func (w chanWriter) Write(data []byte) (int, error) {
+	w <- append([]byte(nil), data...)
+	return len(data), nil
+}
+
+// streamPair 两个互相连接的RPCProcess,模拟一个会话的两端
+func streamPair(t *testing.T, router process.Router, opts ...process.ProcessOption) (cli, svr *RPCProcess) {
+	newProc := func(out chanWriter) *RPCProcess {
+		return NewRPCProcess(
+			process.NewInnerOptions(
+				process.WithInnerOptionOutput(out),
+				process.WithInnerOptionRouter(router),
+			),
+			process.NewProcessOptions(append([]process.ProcessOption{
+				process.WithLogger(zaplog.NewLogger(zap.NewNop())),
+				process.WithMsgCodec(message.JSONCodec),
+				process.WithMetrics(true),
+			}, opts...)...),
+		)
+	}
+	c2s, s2c := make(chanWriter, 1024), make(chanWriter, 1024)
+	cli, svr = newProc(c2s), newProc(s2c)
+	read := func(p *RPCProcess, in chanWriter) {
+		for data := range in {
+			p.OnRead(data)
+		}
+	}
+	go read(svr, c2s)
+	go read(cli, s2c)
+	t.Cleanup(func() {
+		close(c2s)
+		close(s2c)
+	})
+	return
+}
+
+type streamMsg struct {
+	V int `json:"v"`
+}
+
+func TestStream(t *testing.T) {
+	router := &process.MixRouter{}
+	var svr *RPCProcess
+	// server streaming
+	router.Register("count", func(ctx process.Context) {
+		st, err := svr.AcceptStream(ctx)
+		if !assert.Nil(t, err) {
+			return
+		}
+		rq := &streamMsg{}
+		assert.Nil(t, st.Recv(rq))
+		for k := 0; k < rq.V; k++ {
+			assert.Nil(t, st.Send(&streamMsg{V: k}))
+		}
+	})
+	// client streaming
+	router.Register("sum", func(ctx process.Context) {
+		st, _ := svr.AcceptStream(ctx)
+		sum := 0
+		for {
+			rq := &streamMsg{}
+			err := st.Recv(rq)
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			sum += rq.V
+		}
+		assert.Nil(t, st.Send(&streamMsg{V: sum}))
+	})
+	// bidi
+	router.Register("echo", func(ctx process.Context) {
+		st, err := svr.AcceptStream(ctx)
+		if err != nil {
+			// 打开方已经关闭
+			assert.Equal(t, errcode.ErrStreamClosed, err)
+			return
+		}
+		assert.Equal(t, []string{"v"}, st.Metadata().Get("k"))
+		for {
+			rq := &streamMsg{}
+			err := st.Recv(rq)
+			if err != nil {
+				assert.Equal(t, io.EOF, err)
+				break
+			}
+			assert.Nil(t, st.Send(rq))
+		}
+		assert.Nil(t, st.CloseSend())
+	})
+	router.Register("fail", func(ctx process.Context) {
+		st, _ := svr.AcceptStream(ctx)
+		st.Close(errcode.ErrTooManyRequests)
+	})
+	// 处理函数直接响应错误(process.Handle)
+	router.Register("respond", func(ctx process.Context) {
+		ctx.Respond(ctx, errcode.ErrNotSupport, nil)
+	})
+	router.Register("wait", func(ctx process.Context) {
+		st, err := svr.AcceptStream(ctx)
+		if err != nil {
+			// 打开方已经取消
+			assert.Equal(t, errcode.ErrStreamClosed, err)
+			return
+		}
+		<-st.Context().Done()
+		assert.NotNil(t, st.Recv(&streamMsg{}))
+	})
+
+	cli, svr := streamPair(t, router)
+	// 窗口为1,每条消息都需要等待对端确认
+	window := DefaultStreamWindow
+	DefaultStreamWindow = 1
+	defer func() { DefaultStreamWindow = window }()
+	ctx := context.Background()
+
+	t.Run("server", func(t *testing.T) {
+		st, err := cli.NewStream(ctx, "count", NewStreamOptions(WithStreamOptionWindow(1)))
+		assert.Nil(t, err)
+		assert.Nil(t, st.Send(&streamMsg{V: 10}))
+		assert.Nil(t, st.CloseSend())
+		for k := 0; k < 10; k++ {
+			rs := &streamMsg{}
+			assert.Nil(t, st.Recv(rs))
+			assert.Equal(t, k, rs.V)
+		}
+		assert.Equal(t, io.EOF, st.Recv(&streamMsg{}))
+		assert.Equal(t, io.EOF, st.Send(&streamMsg{}))
+	})
+
+	t.Run("client", func(t *testing.T) {
+		st, err := cli.NewStream(ctx, "sum", NewStreamOptions())
+		assert.Nil(t, err)
+		for k := 1; k <= 10; k++ {
+			assert.Nil(t, st.Send(&streamMsg{V: k}))
+		}
+		assert.Nil(t, st.CloseSend())
+		assert.Equal(t, errcode.ErrStreamClosed, st.Send(&streamMsg{}))
+		rs := &streamMsg{}
+		assert.Nil(t, st.Recv(rs))
+		assert.Equal(t, 55, rs.V)
+		assert.Equal(t, io.EOF, st.Recv(rs))
+	})
+
+	t.Run("bidi", func(t *testing.T) {
+		// 同时打开多个流
+		streams := make([]Stream, 3)
+		for k := range streams {
+			st, err := cli.NewStream(ctx, "echo", NewStreamOptions(WithStreamOptionMetadata(metadata.Pairs("k", "v"))))
+			assert.Nil(t, err)
+			streams[k] = st
+		}
+		for k := 0; k < 5; k++ {
+			for i, st := range streams {
+				assert.Nil(t, st.Send(&streamMsg{V: i*10 + k}))
+				rs := &streamMsg{}
+				assert.Nil(t, st.Recv(rs))
+				assert.Equal(t, i*10+k, rs.V)
+			}
+		}
+		for _, st := range streams {
+			assert.Nil(t, st.CloseSend())
+			assert.Equal(t, io.EOF, st.Recv(&streamMsg{}))
+			<-st.Context().Done()
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		st, err := cli.NewStream(ctx, "fail", NewStreamOptions())
+		assert.Nil(t, err)
+		assert.Equal(t, errcode.ErrTooManyRequests, st.Recv(&streamMsg{}))
+		assert.Equal(t, errcode.ErrTooManyRequests, st.Send(&streamMsg{}))
+
+		st, err = cli.NewStream(ctx, "not-found", NewStreamOptions())
+		assert.Nil(t, err)
+		assert.NotNil(t, st.Recv(&streamMsg{}))
+
+		st, err = cli.NewStream(ctx, "respond", NewStreamOptions())
+		assert.Nil(t, err)
+		assert.Equal(t, errcode.ErrNotSupport, st.Recv(&streamMsg{}))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		st, err := cli.NewStream(cctx, "wait", NewStreamOptions())
+		assert.Nil(t, err)
+		cancel()
+		assert.Equal(t, errcode.ErrCanceled, st.Recv(&streamMsg{}))
+
+		st, err = cli.NewStream(ctx, "wait", NewStreamOptions(WithStreamOptionTimeout(time.Millisecond*20)))
+		assert.Nil(t, err)
+		assert.Equal(t, errcode.ErrTimeout, st.Recv(&streamMsg{}))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		max := MaxConcurrentStreams
+		MaxConcurrentStreams = 1
+		defer func() { MaxConcurrentStreams = max }()
+		cctx, cancel := context.WithCancel(ctx)
+		first, err := cli.NewStream(cctx, "wait", NewStreamOptions())
+		assert.Nil(t, err)
+		st, err := cli.NewStream(ctx, "wait", NewStreamOptions())
+		assert.Nil(t, err)
+		assert.Equal(t, errcode.ErrTooManyRequests, st.Recv(&streamMsg{}))
+		cancel()
+		assert.Equal(t, errcode.ErrCanceled, first.Recv(&streamMsg{}))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		// Close的错误记录到监控指标
+		code := strconv.FormatUint(uint64(errcode.Code(errcode.ErrNotSupport)), 10)
+		errs := metricClientErrors.With("echo", "stream", code).Value()
+		st, err := cli.NewStream(ctx, "echo", NewStreamOptions(WithStreamOptionMetadata(metadata.Pairs("k", "v"))))
+		assert.Nil(t, err)
+		assert.Nil(t, st.Close(errcode.ErrNotSupport))
+		assert.Equal(t, errcode.ErrStreamClosed, st.Recv(&streamMsg{}))
+		assert.Equal(t, errs+1, metricClientErrors.With("echo", "stream", code).Value())
+	})
+
+	// 所有流已经结束
+	time.Sleep(time.Millisecond * 20)
+	for _, p := range []*RPCProcess{cli, svr} {
+		p.smux.Lock()
+		assert.Empty(t, p.streams)
+		assert.Zero(t, p.accepted)
+		p.smux.Unlock()
+	}
+}
+
+type rejectExecutor struct{}
+
+func (rejectExecutor) Dispatch(inner *process.InnerOptions, pkg interface{}, next process.PacketDispatcherFunc) error {
+	return process.ErrExecutorQueueFull
+}
+
+func TestStream_Executor(t *testing.T) {
+	router := &process.MixRouter{}
+	var svr *RPCProcess
+	router.Register("echo", func(ctx process.Context) {
+		st, err := svr.AcceptStream(ctx)
+		if !assert.Nil(t, err) {
+			return
+		}
+		rq := &streamMsg{}
+		assert.Nil(t, st.Recv(rq))
+		assert.Nil(t, st.Send(rq))
+	})
+	ctx := context.Background()
+	// 打开流的请求在执行器内处理
+	cli, svr := streamPair(t, router, process.WithExecutor(process.NewMailboxExecutor()))
+	st, err := cli.NewStream(ctx, "echo", NewStreamOptions())
+	assert.Nil(t, err)
+	assert.Nil(t, st.Send(&streamMsg{V: 1}))
+	rs := &streamMsg{}
+	assert.Nil(t, st.Recv(rs))
+	assert.Equal(t, 1, rs.V)
+	assert.Equal(t, io.EOF, st.Recv(rs))
+
+	// 执行器拒绝,打开方收到错误
+	cli, svr = streamPair(t, router, process.WithExecutor(rejectExecutor{}))
+	st, err = cli.NewStream(ctx, "echo", NewStreamOptions())
+	assert.Nil(t, err)
+	err = st.Recv(rs)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), process.ErrExecutorQueueFull.Error())
+	}
+	time.Sleep(time.Millisecond * 20)
+	svr.smux.Lock()
+	assert.Empty(t, svr.streams)
+	svr.smux.Unlock()
+}
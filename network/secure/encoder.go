@@ -0,0 +1,118 @@
+package secure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"sync"
+
+	"github.com/walleframe/walle/process/packet"
+	"go.uber.org/atomic"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// 加密包格式: 4byte size(BigEndian,不包含自身) 8byte sequence 密文(包含16byte认证标签).
+// size与 BytesURICodec 一致,gotcp按照相同的方式分包. nonce为 4byte 0 + 8byte sequence,
+// 两个方向使用不同的密钥,sequence从1开始递增,接收方使用滑动窗口拒绝重放的包.
+const (
+	frameHead    = 4 + 8
+	replayWindow = 64
+)
+
+// NewEncoder 使用指定密钥创建会话加密编码器. sendKey 用于加密发送的包, recvKey 用于解密接收的包.
+// 解密失败(篡改,重放,密钥不匹配)的包 Decode 返回nil.
+func NewEncoder(suite Suite, sendKey, recvKey []byte) (packet.Encoder, error) {
+	send, err := newAEAD(suite, sendKey)
+	if err != nil {
+		return nil, err
+	}
+	recv, err := newAEAD(suite, recvKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encoder{send: send, recv: recv}, nil
+}
+
+func newAEAD(suite Suite, key []byte) (cipher.AEAD, error) {
+	switch suite {
+	case SuiteAES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case SuiteChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, ErrUnsupportedSuite
+}
+
+type encoder struct {
+	send    cipher.AEAD
+	recv    cipher.AEAD
+	sendSeq atomic.Uint64
+	// 接收窗口
+	mux    sync.Mutex
+	maxSeq uint64
+	bitmap uint64
+}
+
+var _ packet.Encoder = (*encoder)(nil)
+
+func (enc *encoder) Encode(buf []byte) []byte {
+	seq := enc.sendSeq.Inc()
+	out := make([]byte, frameHead, frameHead+len(buf)+enc.send.Overhead())
+	binary.BigEndian.PutUint32(out, uint32(cap(out)-4))
+	binary.BigEndian.PutUint64(out[4:], seq)
+	return enc.send.Seal(out, nonce(seq), buf, out[:frameHead])
+}
+
+func (enc *encoder) Decode(buf []byte) []byte {
+	if len(buf) < frameHead+enc.recv.Overhead() || int(binary.BigEndian.Uint32(buf)) != len(buf)-4 {
+		return nil
+	}
+	seq := binary.BigEndian.Uint64(buf[4:])
+	enc.mux.Lock()
+	defer enc.mux.Unlock()
+	if !enc.acceptable(seq) {
+		return nil
+	}
+	out, err := enc.recv.Open(nil, nonce(seq), buf[frameHead:], buf[:frameHead])
+	if err != nil {
+		return nil
+	}
+	enc.mark(seq)
+	return out
+}
+
+// acceptable sequence没有接收过并且在窗口内
+func (enc *encoder) acceptable(seq uint64) bool {
+	if seq == 0 {
+		return false
+	}
+	if seq > enc.maxSeq {
+		return true
+	}
+	diff := enc.maxSeq - seq
+	return diff < replayWindow && enc.bitmap&(1<<diff) == 0
+}
+
+func (enc *encoder) mark(seq uint64) {
+	if seq > enc.maxSeq {
+		shift := seq - enc.maxSeq
+		if shift >= replayWindow {
+			enc.bitmap = 1
+		} else {
+			enc.bitmap = enc.bitmap<<shift | 1
+		}
+		enc.maxSeq = seq
+		return
+	}
+	enc.bitmap |= 1 << (enc.maxSeq - seq)
+}
+
+func nonce(seq uint64) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[4:], seq)
+	return n
+}
@@ -0,0 +1,166 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/walleframe/walle/network"
+	"github.com/walleframe/walle/process/packet"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// 握手错误
+var (
+	ErrInvalidHello     = errors.New("secure: invalid handshake hello")
+	ErrUnsupportedSuite = errors.New("secure: unsupported cipher suite")
+)
+
+// Suite 加密算法
+type Suite uint8
+
+const (
+	// SuiteAES256GCM AES-256-GCM
+	SuiteAES256GCM Suite = 1
+	// SuiteChaCha20Poly1305 ChaCha20-Poly1305, 没有AES硬件加速的客户端(移动端)建议使用
+	SuiteChaCha20Poly1305 Suite = 2
+)
+
+func (s Suite) String() string {
+	switch s {
+	case SuiteAES256GCM:
+		return "AES-256-GCM"
+	case SuiteChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	}
+	return "unknown"
+}
+
+// hello 握手消息: 4byte magic 1byte version 1byte suite 32byte X25519公钥
+const (
+	helloMagic   = "WLSE"
+	helloVersion = 1
+	helloSize    = 4 + 1 + 1 + curve25519.PointSize
+	keySize      = 32
+)
+
+// ClientHandshake 客户端握手: 发送临时公钥和加密算法,等待服务端回复公钥.
+// 每次链接(包括重连)都使用新的临时密钥.
+func ClientHandshake(suite Suite) network.Handshake {
+	return func(rw io.ReadWriter) (packet.Encoder, error) {
+		if !supported(suite) {
+			return nil, ErrUnsupportedSuite
+		}
+		priv, hello, err := newHello(suite)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = rw.Write(hello); err != nil {
+			return nil, err
+		}
+		reply, err := readHello(rw)
+		if err != nil {
+			return nil, err
+		}
+		if Suite(reply[5]) != suite {
+			return nil, ErrUnsupportedSuite
+		}
+		c2s, s2c, err := deriveKeys(priv, reply[6:], hello, reply)
+		if err != nil {
+			return nil, err
+		}
+		return NewEncoder(suite, c2s, s2c)
+	}
+}
+
+// ServerHandshake 服务端握手: 读取客户端公钥,回复服务端临时公钥.
+// suites 允许使用的加密算法,为空时允许全部.
+// NOTE: 临时密钥交换不验证对方身份,不能防御中间人攻击.
+func ServerHandshake(suites ...Suite) network.Handshake {
+	return func(rw io.ReadWriter) (packet.Encoder, error) {
+		hello, err := readHello(rw)
+		if err != nil {
+			return nil, err
+		}
+		suite := Suite(hello[5])
+		if !supported(suite) || !allowed(suites, suite) {
+			return nil, ErrUnsupportedSuite
+		}
+		priv, reply, err := newHello(suite)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = rw.Write(reply); err != nil {
+			return nil, err
+		}
+		c2s, s2c, err := deriveKeys(priv, hello[6:], hello, reply)
+		if err != nil {
+			return nil, err
+		}
+		return NewEncoder(suite, s2c, c2s)
+	}
+}
+
+func supported(suite Suite) bool {
+	return suite == SuiteAES256GCM || suite == SuiteChaCha20Poly1305
+}
+
+func allowed(suites []Suite, suite Suite) bool {
+	if len(suites) == 0 {
+		return true
+	}
+	for _, v := range suites {
+		if v == suite {
+			return true
+		}
+	}
+	return false
+}
+
+func newHello(suite Suite) (priv, hello []byte, err error) {
+	priv = make([]byte, curve25519.ScalarSize)
+	if _, err = rand.Read(priv); err != nil {
+		return
+	}
+	pub, err := curve25519.X25519(priv, curve25519.Basepoint)
+	if err != nil {
+		return
+	}
+	hello = make([]byte, 0, helloSize)
+	hello = append(hello, helloMagic...)
+	hello = append(hello, helloVersion, byte(suite))
+	hello = append(hello, pub...)
+	return
+}
+
+func readHello(r io.Reader) (hello []byte, err error) {
+	hello = make([]byte, helloSize)
+	if _, err = io.ReadFull(r, hello); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hello[:4], []byte(helloMagic)) || hello[4] != helloVersion {
+		return nil, ErrInvalidHello
+	}
+	return
+}
+
+// deriveKeys 根据共享密钥和双方握手消息生成两个方向的密钥
+func deriveKeys(priv, peer, clientHello, serverHello []byte) (c2s, s2c []byte, err error) {
+	// 对方公钥为低阶点时返回错误
+	shared, err := curve25519.X25519(priv, peer)
+	if err != nil {
+		return
+	}
+	info := make([]byte, 0, len(helloMagic)+len(clientHello)+len(serverHello))
+	info = append(info, helloMagic...)
+	info = append(info, clientHello...)
+	info = append(info, serverHello...)
+	keys := make([]byte, keySize*2)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, shared, nil, info), keys); err != nil {
+		return
+	}
+	return keys[:keySize], keys[keySize:], nil
+}
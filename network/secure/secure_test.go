@@ -0,0 +1,85 @@
+package secure
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/packet"
+)
+
+func handshakePair(t *testing.T, client, server Suite) (cli, svr packet.Encoder, cerr, serr error) {
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svr, serr = ServerHandshake(server)(s)
+		if serr != nil {
+			s.Close()
+		}
+	}()
+	cli, cerr = ClientHandshake(client)(c)
+	if cerr != nil {
+		c.Close()
+	}
+	<-done
+	return
+}
+
+func TestHandshake(t *testing.T) {
+	for _, suite := range []Suite{SuiteAES256GCM, SuiteChaCha20Poly1305} {
+		cli, svr, cerr, serr := handshakePair(t, suite, suite)
+		if !assert.Nil(t, cerr, suite) || !assert.Nil(t, serr, suite) {
+			continue
+		}
+		data := []byte{0, 0, 0, 6, 1, 0, 'h', 'e', 'l', 'l'}
+		enc := cli.Encode(data)
+		assert.NotEqual(t, data, enc[len(enc)-len(data):], "ciphertext")
+		assert.Equal(t, data, svr.Decode(enc), "client to server")
+		assert.Equal(t, data, cli.Decode(svr.Encode(data)), "server to client")
+		// 同方向的密钥不能解密
+		assert.Nil(t, cli.Decode(cli.Encode(data)), "same direction")
+	}
+
+	_, _, cerr, serr := handshakePair(t, SuiteChaCha20Poly1305, SuiteAES256GCM)
+	assert.Equal(t, ErrUnsupportedSuite, serr)
+	assert.NotNil(t, cerr)
+}
+
+func TestEncoderReject(t *testing.T) {
+	key := make([]byte, keySize)
+	send, err := NewEncoder(SuiteAES256GCM, key, key)
+	assert.Nil(t, err)
+	recv, err := NewEncoder(SuiteAES256GCM, key, key)
+	assert.Nil(t, err)
+
+	data := []byte("0123456789")
+	p1 := send.Encode(data)
+	p2 := send.Encode(data)
+	p3 := send.Encode(data)
+
+	// 篡改
+	bad := append([]byte(nil), p1...)
+	bad[len(bad)-1] ^= 1
+	assert.Nil(t, recv.Decode(bad), "tampered")
+	bad = append([]byte(nil), p1...)
+	bad[11] ^= 1
+	assert.Nil(t, recv.Decode(bad), "tampered sequence")
+	assert.Nil(t, recv.Decode(p1[:8]), "short")
+
+	// 窗口内乱序可以接收,重放拒绝
+	assert.Equal(t, data, recv.Decode(p3))
+	assert.Equal(t, data, recv.Decode(p1))
+	assert.Nil(t, recv.Decode(p1), "replay")
+	assert.Equal(t, data, recv.Decode(p2))
+	assert.Nil(t, recv.Decode(p3), "replay")
+
+	// 超出窗口
+	old := send.Encode(data)
+	for k := 0; k < replayWindow; k++ {
+		assert.NotNil(t, recv.Decode(send.Encode(data)))
+	}
+	assert.Nil(t, recv.Decode(old), "out of window")
+}
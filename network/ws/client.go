@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/websocket"
+	"github.com/walleframe/walle/network"
 	"github.com/walleframe/walle/network/rpc"
 	"github.com/walleframe/walle/process"
 )
@@ -21,10 +22,21 @@ func NewClientEx(addr string, head http.Header,
 	if err != nil {
 		return
 	}
+	procOpts := process.NewProcessOptions(svr.ProcessOptions...)
+	if svr.Handshake != nil {
+		enc, err := handshake(conn, svr.Handshake, svr.HandshakeTimeout)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		he := network.NewHandshakeEncoder(procOpts.PacketEncode)
+		he.Store(enc)
+		procOpts.PacketEncode = he
+	}
 	cli = &WsSession{
 		RPCProcess: rpc.NewRPCProcess(
 			inner,
-			process.NewProcessOptions(svr.ProcessOptions...),
+			procOpts,
 		),
 		conn:   conn,
 		logger: svr.FrameLogger,
@@ -37,6 +49,10 @@ func NewClientEx(addr string, head http.Header,
 	cli.opts = svr // TODO 客户端独立配置转换
 	cli.ctx = context.Background()
 	cli.cancel = func() {}
+	// 返回之前创建发送队列,避免Run之前调用Write
+	if svr.WriteMethods == WriteAsync {
+		cli.send = make(chan []byte, svr.SendQueueSize)
+	}
 	go cli.Run()
 	return cli, nil
 }
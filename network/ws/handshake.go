@@ -0,0 +1,55 @@
+package ws
+
+import (
+	"io"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/walleframe/walle/process/packet"
+)
+
+// handshake 读写循环开始之前在链接上执行握手
+func handshake(conn *websocket.Conn, h Handshake, timeout time.Duration) (packet.Encoder, error) {
+	if timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+		defer func() {
+			conn.SetReadDeadline(time.Time{})
+			conn.SetWriteDeadline(time.Time{})
+		}()
+	}
+	return h(&handshakeConn{conn: conn})
+}
+
+// handshakeConn 握手使用的 io.ReadWriter. 每次Write发送一个二进制消息,Read按顺序读取消息内容.
+type handshakeConn struct {
+	conn *websocket.Conn
+	r    io.Reader
+}
+
+func (c *handshakeConn) Read(p []byte) (n int, err error) {
+	for {
+		if c.r == nil {
+			_, c.r, err = c.conn.NextReader()
+			if err != nil {
+				return 0, err
+			}
+		}
+		n, err = c.r.Read(p)
+		if err == io.EOF {
+			c.r = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return
+	}
+}
+
+func (c *handshakeConn) Write(p []byte) (n int, err error) {
+	if err = c.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -55,6 +55,10 @@ type ServerOptions struct {
 	Heartbeat time.Duration
 	// HttpServeMux custom set mux
 	HttpServeMux *http.ServeMux
+	// Handshake 链接建立之后执行握手(例如 secure.ServerHandshake),返回会话独立的编码器. nil 不握手
+	Handshake Handshake
+	// HandshakeTimeout 握手超时时间
+	HandshakeTimeout time.Duration
 }
 
 // Addr Server Addr
@@ -228,6 +232,24 @@ func WithHttpServeMux(v *http.ServeMux) ServerOption {
 	}
 }
 
+// Handshake 链接建立之后执行握手(例如 secure.ServerHandshake),返回会话独立的编码器. nil 不握手
+func WithHandshake(v Handshake) ServerOption {
+	return func(cc *ServerOptions) ServerOption {
+		previous := cc.Handshake
+		cc.Handshake = v
+		return WithHandshake(previous)
+	}
+}
+
+// HandshakeTimeout 握手超时时间
+func WithHandshakeTimeout(v time.Duration) ServerOption {
+	return func(cc *ServerOptions) ServerOption {
+		previous := cc.HandshakeTimeout
+		cc.HandshakeTimeout = v
+		return WithHandshakeTimeout(previous)
+	}
+}
+
 // SetOption modify options
 func (cc *ServerOptions) SetOption(opt ServerOption) {
 	_ = opt(cc)
@@ -290,14 +312,16 @@ func newDefaultServerOptions() *ServerOptions {
 		NewSession: func(in Session, r *http.Request) (Session, error) {
 			return in, nil
 		},
-		StopImmediately: false,
-		ReadTimeout:     0,
-		WriteTimeout:    0,
-		MaxMessageLimit: 0,
-		WriteMethods:    WriteAsync,
-		SendQueueSize:   1024,
-		Heartbeat:       0,
-		HttpServeMux:    http.DefaultServeMux,
+		StopImmediately:  false,
+		ReadTimeout:      0,
+		WriteTimeout:     0,
+		MaxMessageLimit:  0,
+		WriteMethods:     WriteAsync,
+		SendQueueSize:    1024,
+		Heartbeat:        0,
+		HttpServeMux:     http.DefaultServeMux,
+		Handshake:        nil,
+		HandshakeTimeout: time.Second * 10,
 	}
 	return cc
 }
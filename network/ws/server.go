@@ -27,6 +27,7 @@ type (
 	Client         = network.Client
 	ClientContext  = network.ClientContext
 	WriteMethod    = network.WriteMethod
+	Handshake      = network.Handshake
 )
 
 // import const value
@@ -78,6 +79,10 @@ func walleServer() interface{} {
 		"Heartbeat": time.Duration(0),
 		// HttpServeMux custom set mux
 		"HttpServeMux": (*http.ServeMux)(http.DefaultServeMux),
+		// Handshake 链接建立之后执行握手(例如 secure.ServerHandshake),返回会话独立的编码器. nil 不握手
+		"Handshake": Handshake(nil),
+		// HandshakeTimeout 握手超时时间
+		"HandshakeTimeout": time.Duration(time.Second * 10),
 	}
 }
 
@@ -97,6 +102,7 @@ type WsServer struct {
 	server     *http.Server
 	mux        sync.RWMutex
 	clients    map[*WsSession]Session
+	serving    atomic.Bool
 }
 
 func NewServer(opts ...ServerOption) *WsServer {
@@ -119,6 +125,10 @@ func NewServer(opts ...ServerOption) *WsServer {
 
 func (s *WsServer) Serve(ln net.Listener) (err error) {
 	s.opts.HttpServeMux.HandleFunc(s.opts.WsPath, s.HttpServeWs)
+	defer network.RegisterServerMetrics("ws", ln.Addr().String(), s)()
+	// listener up
+	s.serving.Store(true)
+	defer s.serving.Store(false)
 	return s.server.Serve(ln)
 }
 
@@ -129,7 +139,15 @@ func (s *WsServer) Run(addr string) (err error) {
 	} else {
 		s.server.Addr = addr
 	}
-	return s.server.ListenAndServe()
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return
+	}
+	defer network.RegisterServerMetrics("ws", s.server.Addr, s)()
+	// listener up
+	s.serving.Store(true)
+	defer s.serving.Store(false)
+	return s.server.Serve(ln)
 }
 
 // serveWs handles websocket requests from the peer.
@@ -150,6 +168,9 @@ func (s *WsServer) HttpServeWs(w http.ResponseWriter, r *http.Request) {
 			log.Error("close session failed", zap.Error(err))
 		}
 	}()
+	procOpts := process.NewProcessOptions(
+		s.opts.ProcessOptions...,
+	)
 	// new session
 	sess := &WsSession{
 		conn: conn,
@@ -159,15 +180,17 @@ func (s *WsServer) HttpServeWs(w http.ResponseWriter, r *http.Request) {
 				process.WithInnerOptionLoad(&s.pkgLoad),
 				process.WithInnerOptionSequence(&s.sequence),
 			),
-			process.NewProcessOptions(
-				s.opts.ProcessOptions...,
-			),
+			procOpts,
 		),
 		ctx:    context.Background(),
 		cancel: func() {},
 		logger: s.opts.FrameLogger,
 	}
 	sess.opts = s.opts
+	// 加入会话列表之前创建发送队列(Broadcast,监控指标会读取)
+	if s.opts.WriteMethods == WriteAsync {
+		sess.send = make(chan []byte, s.opts.SendQueueSize)
+	}
 	sess.Inner.ApplyOption(
 		process.WithInnerOptionContextPool(GoServerContextPool),
 		process.WithInnerOptionOutput(sess),
@@ -178,6 +201,18 @@ func (s *WsServer) HttpServeWs(w http.ResponseWriter, r *http.Request) {
 		// cleanup()
 		return
 	}
+	// handshake before session visible(Broadcast)
+	if s.opts.Handshake != nil {
+		enc, err := handshake(conn, s.opts.Handshake, s.opts.HandshakeTimeout)
+		if err != nil {
+			log.Warn("handshake failed", zap.Error(err), zap.Stringer("remote", conn.RemoteAddr()))
+			return
+		}
+		he := network.NewHandshakeEncoder(procOpts.PacketEncode)
+		he.Store(enc)
+		procOpts.PacketEncode = he
+		sess.encoder = enc
+	}
 	// maybe cusotm session
 	newSess, err := s.opts.NewSession(sess, r)
 	if err != nil {
@@ -246,7 +281,7 @@ func (s *WsServer) Broadcast(uri interface{}, msg interface{}, md metadata.MD) e
 	data = s.procOpts.PacketEncode.Encode(data)
 
 	for cli := range s.clients {
-		cli.Write(data)
+		cli.Write(network.EncodeSession(cli, data))
 	}
 	return nil
 }
@@ -278,7 +313,7 @@ func (s *WsServer) BroadcastFilter(filter func(Session) bool, uri interface{}, m
 		if filter(cli) {
 			continue
 		}
-		cli.Write(data)
+		cli.Write(network.EncodeSession(cli, data))
 	}
 	return nil
 }
@@ -293,6 +328,12 @@ func (s *WsServer) ForEach(f func(Session)) {
 		f(cli)
 	}
 }
+
+// Serving listener accepting connections
+func (s *WsServer) Serving() bool {
+	return s.serving.Load()
+}
+
 func (s *WsServer) Shutdown(ctx context.Context) (err error) {
 	err = s.server.Shutdown(ctx)
 	s.mux.Lock()
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"net"
 
 	"github.com/walleframe/walle/app"
@@ -36,6 +37,20 @@ func (svc *WsService) Stop() {
 	svc.svr.Shutdown(context.Background())
 	return
 }
+func (svc *WsService) StopContext(ctx context.Context) {
+	svc.svr.Shutdown(ctx)
+	return
+}
 func (svc *WsService) Finish() {
 	return
 }
+
+// Ready 服务正在接收连接
+func (svc *WsService) Ready(ctx context.Context) error {
+	if !svc.svr.Serving() {
+		return ErrNotServing
+	}
+	return nil
+}
+
+var ErrNotServing = errors.New("server not serving")
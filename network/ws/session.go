@@ -9,6 +9,7 @@ import (
 	"github.com/walleframe/walle/network/rpc"
 	"github.com/walleframe/walle/process"
 	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
 	"github.com/walleframe/walle/zaplog"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -36,6 +37,8 @@ type WsSession struct {
 	closeClient []func(Client)
 	//
 	logger *zaplog.Logger
+	// 握手生成的会话编码器
+	encoder packet.Encoder
 }
 
 func (sess *WsSession) Write(in []byte) (n int, err error) {
@@ -96,11 +99,23 @@ func (sess *WsSession) GetServer() Server {
 	return sess.svr
 }
 
+// SessionEncoder 握手生成的会话编码器,没有握手返回nil
+func (sess *WsSession) SessionEncoder() packet.Encoder {
+	return sess.encoder
+}
+
+// SendQueueLen 异步发送队列中等待发送的消息数量
+func (sess *WsSession) SendQueueLen() int {
+	return len(sess.send)
+}
+
 // Run run client
 func (sess *WsSession) Run() {
 	// async write
 	if sess.opts.WriteMethods == WriteAsync {
-		sess.send = make(chan []byte, sess.opts.SendQueueSize)
+		if sess.send == nil {
+			sess.send = make(chan []byte, sess.opts.SendQueueSize)
+		}
 		go sess.writeLoop()
 	}
 	sess.readLoop()
@@ -247,6 +262,8 @@ func (p *goServerContextPool) NewContext(inner *process.InnerOptions, opts *proc
 	ctx.LoadFlag = loadFlag
 	ctx.Log = opts.Logger
 	ctx.FreeContext = ctx
+	ctx.RouterParams = nil
+	ctx.CancelTimeout = nil
 	ctx.WsSession = inner.BindData.(*WsSession)
 	return ctx
 }
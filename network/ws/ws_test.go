@@ -3,6 +3,7 @@ package ws
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"runtime"
 	"sync"
 	"testing"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/walleframe/walle/network/rpc"
+	"github.com/walleframe/walle/network/secure"
 	"github.com/walleframe/walle/process"
 	"github.com/walleframe/walle/testpkg/wpb"
 	"github.com/walleframe/walle/util"
@@ -161,3 +163,50 @@ func BenchmarkGoTCPClient(b *testing.B) {
 	})
 
 }
+
+func TestWsSecure(t *testing.T) {
+	p, err := util.GetFreePort()
+	assert.Nil(t, err, "get free port")
+	svc := NewServer(
+		WithHttpServeMux(http.NewServeMux()),
+		WithHandshake(secure.ServerHandshake()),
+	)
+	go svc.Run(fmt.Sprintf(":%d", p))
+	defer svc.Shutdown(context.Background())
+	time.Sleep(time.Millisecond * 50)
+
+	cli, err := NewClientEx(fmt.Sprintf("ws://localhost:%d/ws", p), nil, process.NewInnerOptions(),
+		NewServerOptions(WithHandshake(secure.ClientHandshake(secure.SuiteChaCha20Poly1305))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	mulRs, err := wpb.NewWSvcClient(cli).Mul(context.Background(), &wpb.MulRq{A: 100, B: 5})
+	assert.Nil(t, err, "call rpc mul error")
+	if assert.NotNil(t, mulRs) {
+		assert.EqualValues(t, 500, mulRs.R, "rpc mul return value")
+	}
+}
+
+func TestWsServiceReady(t *testing.T) {
+	p, err := util.GetFreePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService("ws", WithAddr(fmt.Sprintf(":%d", p)), WithHttpServeMux(http.NewServeMux()))
+	checker := svc.(*WsService)
+	ctx := context.Background()
+	assert.Equal(t, ErrNotServing, checker.Ready(ctx), "not ready before start")
+	assert.Nil(t, svc.Init(nil))
+	assert.Nil(t, svc.Start(nil))
+	for i := 0; i < 100 && checker.Ready(ctx) != nil; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, checker.Ready(ctx), "ready after start")
+	svc.Stop()
+	for i := 0; i < 100 && checker.Ready(ctx) == nil; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, ErrNotServing, checker.Ready(ctx), "not ready after stop")
+}
@@ -35,7 +35,13 @@ type Context interface {
 	GetRequestPacket() interface{}
 	// GetReqeustMD get request metadata
 	GetReqeustMD() (metadata.MD, error)
-	// Bind use for unmarshal packet body
+	// Params get router path params
+	Params() Params
+	// Param get router path param by name
+	Param(name string) string
+	// SetParams set router path params
+	SetParams(params Params)
+	// Bind use for unmarshal packet body, then validate body if Opts.Validator set
 	Bind(body interface{}) (err error)
 	// Respond write response.
 	Respond(_ context.Context, body interface{}, md metadata.MD) (err error)
@@ -31,6 +31,10 @@ type WrapContext struct {
 	Log *zaplog.Logger
 	// use to free context
 	FreeContext Context
+	// router path params
+	RouterParams Params
+	// cancel request deadline when free context
+	CancelTimeout context.CancelFunc
 }
 
 // WithValue wrap context.WithValue
@@ -57,6 +61,11 @@ func (ctx *WrapContext) WithTimeout(timeout time.Duration) (_ Context, cancel fu
 	return ctx, cancel
 }
 
+// setTimeout 设置处理流程截止时间,处理流程结束(回收Context)时取消
+func (ctx *WrapContext) setTimeout(timeout time.Duration) {
+	ctx.SrcContext, ctx.CancelTimeout = context.WithTimeout(ctx.SrcContext, timeout)
+}
+
 // Deadline wrap context.Context.Deadline
 func (ctx *WrapContext) Deadline() (deadline time.Time, ok bool) {
 	return ctx.SrcContext.Deadline()
@@ -87,9 +96,28 @@ func (ctx *WrapContext) GetReqeustMD() (metadata.MD, error) {
 	return ctx.Opts.PacketWraper.GetMetadata(ctx.InPkg)
 }
 
-// Bind use for unmarshal packet body
+// Params get router path params
+func (ctx *WrapContext) Params() Params {
+	return ctx.RouterParams
+}
+
+// Param get router path param by name
+func (ctx *WrapContext) Param(name string) string {
+	return ctx.RouterParams.ByName(name)
+}
+
+// SetParams set router path params
+func (ctx *WrapContext) SetParams(params Params) {
+	ctx.RouterParams = params
+}
+
+// Bind use for unmarshal packet body, then validate body if Opts.Validator set
 func (ctx *WrapContext) Bind(body interface{}) (err error) {
-	return ctx.Opts.PacketWraper.PayloadUnmarshal(ctx.InPkg, ctx.Opts.MsgCodec, body)
+	err = ctx.Opts.PacketWraper.PayloadUnmarshal(ctx.InPkg, ctx.Opts.MsgCodec, body)
+	if err != nil {
+		return
+	}
+	return ctx.validate(body)
 }
 
 // Respond write response.
@@ -98,6 +126,11 @@ func (ctx *WrapContext) Respond(_ context.Context, body interface{}, md metadata
 		err = errcode.ErrUnexpectedCode
 		return
 	}
+	if hooks, ok := ctx.SrcContext.Value(respondHooksKey{}).([]RespondHook); ok {
+		for _, hook := range hooks {
+			md = hook(body, md)
+		}
+	}
 	wp := ctx.Opts.PacketWraper
 	outPkg := ctx.Opts.PacketPool.Get()
 	err = wp.NewResponse(ctx.InPkg, outPkg, md)
@@ -112,6 +145,7 @@ func (ctx *WrapContext) Respond(_ context.Context, body interface{}, md metadata
 	if err != nil {
 		return
 	}
+	data = ctx.Opts.PacketEncode.Encode(data)
 	_, err = ctx.Inner.Output.Write(data)
 	ctx.Opts.PacketPool.Put(outPkg)
 	return
@@ -134,6 +168,11 @@ func (ctx *WrapContext) Next(nctx Context) {
 			}
 			ctx.InPkg = nil
 		}
+		ctx.RouterParams = nil
+		if ctx.CancelTimeout != nil {
+			ctx.CancelTimeout()
+			ctx.CancelTimeout = nil
+		}
 		if ctx.FreeContext != nil {
 			ctx.Inner.ContextPool.FreeContext(ctx.FreeContext)
 		}
@@ -166,6 +205,23 @@ func (ctx *WrapContext) NewEntry(funcName string) *zaplog.LogEntities {
 	return ctx.NewEntry(funcName)
 }
 
+// RespondHook 响应钩子. 写入响应之前调用,可以记录响应结果或者修改响应metadata.
+type RespondHook func(body interface{}, md metadata.MD) metadata.MD
+
+type respondHooksKey struct{}
+
+// AddRespondHook 添加响应钩子,仅对当前请求生效.
+// 中间件不需要包装Context(包装之后无法获取会话等具体类型).
+func AddRespondHook(ctx Context, hook RespondHook) {
+	hooks, _ := ctx.Value(respondHooksKey{}).([]RespondHook)
+	ctx.WithValue(respondHooksKey{}, append(hooks[:len(hooks):len(hooks)], hook))
+}
+
+// timeoutSetter 设置处理流程截止时间. 内嵌 WrapContext 的会话Context同样实现此接口
+type timeoutSetter interface {
+	setTimeout(timeout time.Duration)
+}
+
 type ContextPool interface {
 	NewContext(inner *InnerOptions, opts *ProcessOptions, inPkg interface{}, handlers []MiddlewareFunc, loadFlag bool) Context
 	FreeContext(Context)
@@ -185,6 +241,8 @@ func (p *wrapContextPool) NewContext(inner *InnerOptions, opts *ProcessOptions,
 	ctx.LoadFlag = loadFlag
 	ctx.Log = opts.Logger
 	ctx.FreeContext = ctx
+	ctx.RouterParams = nil
+	ctx.CancelTimeout = nil
 	return ctx
 }
 
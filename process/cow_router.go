@@ -0,0 +1,236 @@
+package process
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/walleframe/walle/process/errcode"
+)
+
+// COWRouter 写时复制路由. 支持在使用过程中注册,删除和替换路由(功能开关,GM禁用命令,插件模块等).
+// 修改写入待发布路由表(TrieRouter),查找时发布. GetHandlers 无锁读取当前路由表.
+// 连续注册增量修改同一个待发布路由表,只有删除,替换和修改中间件需要重新生成.
+type COWRouter struct {
+	mux         sync.Mutex
+	middlewares []MiddlewareFunc
+	routes      map[interface{}]*routerNode
+	noCache     []MiddlewareFunc
+	table       atomic.Value // *TrieRouter
+	// next 待发布路由表,发布之后不再修改
+	next  *TrieRouter
+	dirty int32
+}
+
+var _ ParamsRouter = (*COWRouter)(nil)
+
+// NewCOWRouter new copy-on-write router
+func NewCOWRouter() *COWRouter {
+	r := &COWRouter{
+		routes: make(map[interface{}]*routerNode),
+	}
+	r.table.Store(&TrieRouter{})
+	return r
+}
+
+// Use 设置全局中间件，在Use之后注册的接口都会使用此中间件
+func (r *COWRouter) Use(m ...MiddlewareFunc) {
+	if len(m) < 1 {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.middlewares = append(r.middlewares, m...)
+}
+
+// NoRouter 未设置路由请求
+func (r *COWRouter) NoRouter(rf RouterFunc, mid ...MiddlewareFunc) (err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.noCache != nil {
+		err = fmt.Errorf("norouter %w", ErrRouterKeyRepated)
+		return
+	}
+	noCache := make([]MiddlewareFunc, 0, len(r.middlewares)+len(mid)+1)
+	noCache = append(noCache, r.middlewares...)
+	noCache = append(noCache, mid...)
+	noCache = append(noCache, rf)
+	r.noCache = noCache
+	return r.rebuild()
+}
+
+// Register 注册路由. uri 支持 string(路径模式), uint32/int(消息ID)
+func (r *COWRouter) Register(uri interface{}, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+	return r.registerNode(uri, r.newNode(nil, rf, m...))
+}
+
+// Replace 替换路由,路由不存在时注册. 替换分组内的路由时保留原分组中间件.
+func (r *COWRouter) Replace(uri interface{}, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+	key, err := routeKey(uri)
+	if err != nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	old := r.routes[key]
+	var group *routerGroup
+	if old != nil {
+		group = old.group
+	}
+	node := newRouterNode(r.middlewares, group, rf, m...)
+	node.stat = newRouteStat(key)
+	r.routes[key] = node
+	if err = r.rebuild(); err != nil {
+		if old != nil {
+			r.routes[key] = old
+		} else {
+			delete(r.routes, key)
+		}
+		return warnRegister(uri, node, err)
+	}
+	if group != nil {
+		group.replaceNode(old, node)
+	}
+	return
+}
+
+// Unregister 删除路由
+func (r *COWRouter) Unregister(uri interface{}) (err error) {
+	key, err := routeKey(uri)
+	if err != nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	node, ok := r.routes[key]
+	if !ok {
+		return fmt.Errorf("uri %v %w", uri, ErrRouterNotSupport)
+	}
+	delete(r.routes, key)
+	if node.group != nil {
+		node.group.removeNode(node)
+	}
+	return r.rebuild()
+}
+
+// Group 新建路由分组
+func (r *COWRouter) Group(prefix string, m ...MiddlewareFunc) Router {
+	return newRouterGroup(r, nil, prefix, m...)
+}
+
+// GetHandlers 获取请求对应处理函数
+func (r *COWRouter) GetHandlers(in interface{}) (handlers []RouterFunc, err error) {
+	return r.load().GetHandlers(in)
+}
+
+// GetHandlersParams 获取请求对应处理函数和路径参数
+func (r *COWRouter) GetHandlersParams(in interface{}) (handlers []RouterFunc, params Params, err error) {
+	return r.load().GetHandlersParams(in)
+}
+
+func (r *COWRouter) getHandlersStat(in interface{}) (handlers []RouterFunc, params Params, stat *routeStat, err error) {
+	return r.load().getHandlersStat(in)
+}
+
+// Routes 已注册路由
+func (r *COWRouter) Routes() (routes []RouteInfo) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for key, node := range r.routes {
+		routes = append(routes, routeInfo(key, node.funs))
+	}
+	return append(routes, noRouterInfo(r.noCache)...)
+}
+
+func (r *COWRouter) newNode(group *routerGroup, rf RouterFunc, m ...MiddlewareFunc) *routerNode {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return newRouterNode(r.middlewares, group, rf, m...)
+}
+
+func (r *COWRouter) registerNode(uri interface{}, node *routerNode) (err error) {
+	key, err := routeKey(uri)
+	if err != nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if exist, ok := r.routes[key]; ok {
+		return warnRegister(uri, node, fmt.Errorf("uri %v registered by %s, %w", uri, exist.name(), ErrRouterKeyRepated))
+	}
+	node.stat = newRouteStat(key)
+	r.routes[key] = node
+	if err = r.add(key, node); err != nil {
+		delete(r.routes, key)
+		// 增量修改失败,丢弃修改了一半的待发布路由表
+		if r.next != nil {
+			r.next = nil
+			r.rebuild()
+		}
+		return
+	}
+	if node.group != nil {
+		node.group.nodes = append(node.group.nodes, node)
+	}
+	return
+}
+
+// update 修改路由分组之后重新生成路由表
+func (r *COWRouter) update(f func()) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	f()
+	return r.rebuild()
+}
+
+// add 路由增量注册到待发布路由表
+func (r *COWRouter) add(key interface{}, node *routerNode) (err error) {
+	if r.next == nil {
+		return r.rebuild()
+	}
+	return r.next.registerNode(key, &routerNode{funs: node.funs, stat: node.stat})
+}
+
+// rebuild 重新生成待发布路由表. 路由节点复制一份,修改分组中间件不影响正在使用的路由表.
+func (r *COWRouter) rebuild() (err error) {
+	table := &TrieRouter{noCache: r.noCache}
+	for key, node := range r.routes {
+		err = table.registerNode(key, &routerNode{funs: node.funs, stat: node.stat})
+		if err != nil {
+			return
+		}
+	}
+	r.next = table
+	atomic.StoreInt32(&r.dirty, 1)
+	return
+}
+
+// load 获取当前路由表,有修改时先发布待发布路由表
+func (r *COWRouter) load() *TrieRouter {
+	if atomic.LoadInt32(&r.dirty) != 0 {
+		r.mux.Lock()
+		if r.next != nil {
+			r.table.Store(r.next)
+			r.next = nil
+		}
+		atomic.StoreInt32(&r.dirty, 0)
+		r.mux.Unlock()
+	}
+	return r.table.Load().(*TrieRouter)
+}
+
+// routeKey 路由key. int类型消息ID转换为uint32
+func routeKey(uri interface{}) (key interface{}, err error) {
+	switch v := uri.(type) {
+	case string, uint32:
+		return v, nil
+	case int:
+		return uint32(v), nil
+	default:
+		// 未支持的类型
+		return nil, errcode.WrapError(errcode.ErrUnexpectedCode,
+			fmt.Errorf("register uri type[%s] not suppert", reflect.TypeOf(uri).Name()),
+		)
+	}
+}
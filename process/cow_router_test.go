@@ -0,0 +1,186 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/packet"
+)
+
+func TestCOWRouter(t *testing.T) {
+	r := NewCOWRouter()
+	var called string
+	handler := func(name string) RouterFunc {
+		return func(ctx Context) { called = name }
+	}
+	call := func(uri string, msgID uint32) (params Params, err error) {
+		pkg := packet.NewPacket()
+		pkg.SetURI(uri)
+		pkg.SetMsgID(msgID)
+		called = ""
+		handlers, params, err := r.GetHandlersParams(pkg)
+		for _, h := range handlers {
+			h(nil)
+		}
+		return
+	}
+
+	assert.Nil(t, r.Register("/room/:roomId/chat", handler("chat")))
+	assert.Nil(t, r.Register(1, handler("id")))
+	assert.True(t, errors.Is(r.Register(uint32(1), handler("id")), ErrRouterKeyRepated))
+	assert.True(t, errors.Is(r.Register("/room/:id/info", handler("info")), ErrRouterInvalidPath))
+
+	params, err := call("/room/1/chat", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "chat", called)
+	assert.Equal(t, "1", params.ByName("roomId"))
+	_, err = call("", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "id", called)
+
+	// 替换
+	assert.Nil(t, r.Replace("/room/:roomId/chat", handler("chat2")))
+	call("/room/1/chat", 0)
+	assert.Equal(t, "chat2", called)
+	// 删除
+	assert.Nil(t, r.Unregister(1))
+	_, err = call("", 1)
+	assert.True(t, errors.Is(err, ErrRouterNotSupport))
+	assert.True(t, errors.Is(r.Unregister(1), ErrRouterNotSupport))
+	assert.Nil(t, r.NoRouter(handler("norouter")))
+	call("", 1)
+	assert.Equal(t, "norouter", called)
+
+	// 分组中间件修改
+	var calls []string
+	g := r.Group("/gm")
+	assert.Nil(t, g.Register("/ban", func(ctx Context) { calls = append(calls, "ban") }))
+	g.Use(func(ctx Context) { calls = append(calls, "audit") })
+	call("/gm/ban", 0)
+	assert.Equal(t, []string{"audit", "ban"}, calls)
+
+	// 替换分组内路由,保留分组中间件
+	assert.Nil(t, r.Replace("/gm/ban", func(ctx Context) { calls = append(calls, "ban2") }))
+	calls = nil
+	call("/gm/ban", 0)
+	assert.Equal(t, []string{"audit", "ban2"}, calls)
+	g.Use(func(ctx Context) { calls = append(calls, "log") })
+	calls = nil
+	call("/gm/ban", 0)
+	assert.Equal(t, []string{"audit", "log", "ban2"}, calls)
+	assert.Len(t, g.(*routerGroup).nodes, 1)
+	assert.Nil(t, r.Unregister("/gm/ban"))
+	assert.Empty(t, g.(*routerGroup).nodes)
+}
+
+func TestCOWRouter_Concurrent(t *testing.T) {
+	r := NewCOWRouter()
+	f := func(ctx Context) {}
+	assert.Nil(t, r.Register("/static", f))
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pkg := packet.NewPacket()
+			pkg.SetURI("/static")
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_, err := r.GetHandlers(pkg)
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	g := r.Group("/g")
+	for i := 0; i < 100; i++ {
+		uri := fmt.Sprintf("/uri/%d", i)
+		assert.Nil(t, g.Register(uri, f))
+		g.Use(f)
+		assert.Nil(t, r.Unregister("/g"+uri))
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestCOWRouter_Publish(t *testing.T) {
+	r := NewCOWRouter()
+	f := func(ctx Context) {}
+	lookup := func(msgID uint32) error {
+		pkg := packet.NewPacket()
+		pkg.SetMsgID(msgID)
+		_, err := r.GetHandlers(pkg)
+		return err
+	}
+	assert.Nil(t, r.Register(1, f))
+	next := r.next
+	assert.Nil(t, r.Register(2, f))
+	assert.Nil(t, r.Group("/g").Register(3, f))
+	assert.True(t, next == r.next, "register modify pending table")
+	assert.True(t, errors.Is(r.Register(2, f), ErrRouterKeyRepated))
+	assert.Empty(t, r.table.Load().(*TrieRouter).handlersID, "publish when lookup")
+	// 第一次查找时发布
+	assert.Nil(t, lookup(3))
+	assert.Nil(t, r.next)
+	table := r.table.Load()
+	// 已发布的路由表不再修改
+	assert.Nil(t, r.Register(4, f))
+	assert.True(t, table != r.next)
+	assert.True(t, errors.Is(lookup(5), ErrRouterNotSupport))
+	assert.Nil(t, lookup(4))
+}
+
+func benchmarkRouter(b *testing.B, r Router, uri string, msgID uint32) {
+	f := func(ctx Context) {}
+	for i := 0; i < 100; i++ {
+		r.Register(fmt.Sprintf("/static/%d", i), f)
+		r.Register(i+1, f)
+	}
+	if _, ok := r.(*MixRouter); !ok {
+		r.Register("/room/:roomId/chat", f)
+	}
+	pkg := packet.NewPacket()
+	pkg.SetURI(uri)
+	pkg.SetMsgID(msgID)
+	if _, err := r.GetHandlers(pkg); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.GetHandlers(pkg)
+		}
+	})
+}
+
+func BenchmarkRouter_GetHandlers(b *testing.B) {
+	routers := []struct {
+		name      string
+		newRouter func() Router
+	}{
+		{"mix", func() Router { return &MixRouter{} }},
+		{"trie", func() Router { return NewTrieRouter() }},
+		{"cow", func() Router { return NewCOWRouter() }},
+	}
+	for _, v := range routers {
+		b.Run(v.name+"/msgid", func(b *testing.B) {
+			benchmarkRouter(b, v.newRouter(), "", 50)
+		})
+		b.Run(v.name+"/static", func(b *testing.B) {
+			benchmarkRouter(b, v.newRouter(), "/static/50", 0)
+		})
+		if v.name != "mix" {
+			b.Run(v.name+"/param", func(b *testing.B) {
+				benchmarkRouter(b, v.newRouter(), "/room/10/chat", 0)
+			})
+		}
+	}
+}
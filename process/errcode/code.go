@@ -24,6 +24,18 @@ const (
 	ErrorCodeSessionClosed ErrorCode = 8
 	//
 	ErrorCodeInvalidErrorPayload ErrorCode = 9
+	// handler panic
+	ErrorCodeHandlerPanic ErrorCode = 10
+	// too many requests
+	ErrorCodeTooManyRequests ErrorCode = 11
+	// invalid argument
+	ErrorCodeInvalidArgument ErrorCode = 12
+	// invalid packet format
+	ErrorCodeInvalidPacket ErrorCode = 13
+	// request canceled
+	ErrorCodeCanceled ErrorCode = 14
+	// stream closed
+	ErrorCodeStreamClosed ErrorCode = 15
 )
 
 var (
@@ -45,4 +57,14 @@ var (
 	ErrSessionClosed = NewError(ErrorCodeSessionClosed, "session closed")
 	// ErrInvalidErrPayload error payload invalid
 	ErrInvalidErrPayload = NewError(ErrorCodeInvalidErrorPayload, "error payload invalid")
+	// ErrHandlerPanic handler panic
+	ErrHandlerPanic = NewError(ErrorCodeHandlerPanic, "handler panic")
+	// ErrTooManyRequests rate limited
+	ErrTooManyRequests = NewError(ErrorCodeTooManyRequests, "too many requests")
+	// ErrInvalidPacket malformed packet or metadata, length field out of range
+	ErrInvalidPacket = NewError(ErrorCodeInvalidPacket, "invalid packet format")
+	// ErrCanceled request or stream canceled by peer
+	ErrCanceled = NewError(ErrorCodeCanceled, "canceled")
+	// ErrStreamClosed stream already closed
+	ErrStreamClosed = NewError(ErrorCodeStreamClosed, "stream closed")
 )
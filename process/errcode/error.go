@@ -1,7 +1,9 @@
 package errcode
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/walleframe/walle/util"
@@ -13,6 +15,8 @@ type ErrorResponse struct {
 	Code uint32
 	// desc
 	Desc string
+	// 解码得到的错误详情(*InvalidArgument),使用 errors.As 获取
+	details error
 }
 
 // Error implement error interface.
@@ -36,6 +40,11 @@ func (err *ErrorResponse) Codes() uint32 {
 	return err.Code
 }
 
+// Unwrap 返回错误详情
+func (err *ErrorResponse) Unwrap() error {
+	return err.details
+}
+
 var _ error = (*ErrorResponse)(nil)
 
 func NewError(code ErrorCode, desc string) error {
@@ -45,6 +54,18 @@ func NewError(code ErrorCode, desc string) error {
 	}
 }
 
+// Code 获取错误码. nil 返回 ErrorCodeSuccess,未实现 Codes() 接口的错误返回 ErrorCodeUnkwon
+func Code(err error) uint32 {
+	if err == nil {
+		return uint32(ErrorCodeSuccess)
+	}
+	var code interface{ Codes() uint32 }
+	if errors.As(err, &code) {
+		return code.Codes()
+	}
+	return uint32(ErrorCodeUnkwon)
+}
+
 func Is(err error, code ErrorCode) bool {
 	if c, ok := err.(*ErrorResponse); ok {
 		return c.Code == uint32(code)
@@ -73,16 +94,20 @@ func (errResponseCodec) Marshal(code error) (data []byte, err error) {
 	e, ok := code.(*ErrorResponse)
 	if !ok {
 		tip := code.Error()
-		data = make([]byte, 4+len(tip)) // mempool.Pool().Alloc(4 + len(tip))
+		data = make([]byte, 4, 4+len(tip)) // mempool.Pool().Alloc(4 + len(tip))
 		// code default is 1,unkown error
 		binary.BigEndian.PutUint32(data, 1)
+		// custom error with code
+		if c, ok := code.(interface{ Codes() uint32 }); ok {
+			binary.BigEndian.PutUint32(data, c.Codes())
+		}
 		data = append(data, util.StringToBytes(tip)...)
-		return
+		return appendDetails(data, code), nil
 	}
-	data = make([]byte, 4+len(e.Desc)) // mempool.Pool().Alloc(4 + len(e.Desc))
+	data = make([]byte, 4, 4+len(e.Desc)) // mempool.Pool().Alloc(4 + len(e.Desc))
 	binary.BigEndian.PutUint32(data, e.Code)
 	data = append(data, util.StringToBytes(e.Desc)...)
-	return
+	return appendDetails(data, code), nil
 }
 
 func (errResponseCodec) Unmarshal(data []byte) (err error) {
@@ -92,9 +117,32 @@ func (errResponseCodec) Unmarshal(data []byte) (err error) {
 	e := &ErrorResponse{}
 	e.Code = binary.BigEndian.Uint32(data)
 	e.Desc = string(data[4:])
+	if e.Code != uint32(ErrorCodeInvalidArgument) {
+		return e
+	}
+	// 参数错误: 错误描述 + 0 + 错误详情. 详情解码失败时保留完整描述
+	idx := bytes.IndexByte(data[4:], 0)
+	if idx < 0 {
+		return e
+	}
+	invalid := &InvalidArgument{}
+	if invalid.unmarshal(data[4+idx+1:]) != nil {
+		return e
+	}
+	e.Desc = string(data[4 : 4+idx])
+	e.details = invalid
 	return e
 }
 
+// appendDetails 参数错误在错误描述之后追加错误字段
+func appendDetails(data []byte, code error) []byte {
+	var invalid *InvalidArgument
+	if !errors.As(code, &invalid) {
+		return data
+	}
+	return invalid.appendTo(append(data, 0))
+}
+
 type errResponseNew struct{}
 
 func (errResponseNew) New() error {
@@ -0,0 +1,44 @@
+package errcode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrResponseCodec(t *testing.T) {
+	// 错误码之后直接是错误描述,没有多余的填充字节
+	data, err := DefaultErrorCodec.Marshal(ErrNotSupport)
+	assert.Nil(t, err)
+	assert.Len(t, data, 4+len(ErrNotSupport.(*ErrorResponse).Desc))
+	assert.Equal(t, ErrNotSupport, DefaultErrorCodec.Unmarshal(data))
+
+	data, err = DefaultErrorCodec.Marshal(errors.New("custom"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 1, 'c', 'u', 's', 't', 'o', 'm'}, data)
+	assert.Equal(t, &ErrorResponse{Code: 1, Desc: "custom"}, DefaultErrorCodec.Unmarshal(data))
+
+	assert.Equal(t, ErrPacketsizeInvalid, DefaultErrorCodec.Unmarshal([]byte{0, 1}))
+}
+
+func TestErrResponseCodec_InvalidArgument(t *testing.T) {
+	in := NewInvalidArgument("bad request").Field("name", "required").Field("age", "must be positive")
+	data, err := DefaultErrorCodec.Marshal(in)
+	assert.Nil(t, err)
+	out := DefaultErrorCodec.Unmarshal(data)
+	assert.True(t, Is(out, ErrorCodeInvalidArgument))
+	assert.Equal(t, "12-"+in.Error(), out.Error())
+	var invalid *InvalidArgument
+	if assert.True(t, errors.As(out, &invalid), "violations sent to client") {
+		assert.Equal(t, in, invalid)
+	}
+	// 转发收到的参数错误
+	data, err = DefaultErrorCodec.Marshal(out)
+	assert.Nil(t, err)
+	assert.Equal(t, out, DefaultErrorCodec.Unmarshal(data))
+
+	// 错误详情解码失败,保留完整描述
+	data = []byte{0, 0, 0, 12, 'b', 'a', 'd', 0, 5}
+	assert.Equal(t, &ErrorResponse{Code: 12, Desc: "bad\x00\x05"}, DefaultErrorCodec.Unmarshal(data))
+}
@@ -0,0 +1,121 @@
+package errcode
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// FieldViolation 参数错误字段
+type FieldViolation struct {
+	Field string
+	Desc  string
+}
+
+// InvalidArgument 请求参数错误,包含错误字段详情. 错误码 ErrorCodeInvalidArgument
+// 错误字段随错误响应发送,客户端使用 errors.As 从 *ErrorResponse 获取.
+type InvalidArgument struct {
+	Desc       string
+	Violations []FieldViolation
+}
+
+// NewInvalidArgument 参数错误
+func NewInvalidArgument(desc string) *InvalidArgument {
+	return &InvalidArgument{Desc: desc}
+}
+
+// InvalidField 字段错误
+func InvalidField(field, desc string) *InvalidArgument {
+	return (&InvalidArgument{}).Field(field, desc)
+}
+
+// Field 添加错误字段
+func (e *InvalidArgument) Field(field, desc string) *InvalidArgument {
+	e.Violations = append(e.Violations, FieldViolation{Field: field, Desc: desc})
+	return e
+}
+
+// Error implement error interface.
+func (e *InvalidArgument) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid argument")
+	if e.Desc != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Desc)
+	}
+	for k, v := range e.Violations {
+		if k == 0 {
+			sb.WriteString(" [")
+		} else {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(v.Field)
+		sb.WriteString(": ")
+		sb.WriteString(v.Desc)
+	}
+	if len(e.Violations) > 0 {
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+// Codes error code
+func (e *InvalidArgument) Codes() uint32 {
+	return uint32(ErrorCodeInvalidArgument)
+}
+
+var _ error = (*InvalidArgument)(nil)
+
+// appendTo 编码错误描述和错误字段. 字符串和字段数量使用uvarint长度前缀
+func (e *InvalidArgument) appendTo(data []byte) []byte {
+	data = appendString(data, e.Desc)
+	data = appendUvarint(data, uint64(len(e.Violations)))
+	for _, v := range e.Violations {
+		data = appendString(data, v.Field)
+		data = appendString(data, v.Desc)
+	}
+	return data
+}
+
+// unmarshal 解码 appendTo 编码的数据
+func (e *InvalidArgument) unmarshal(data []byte) (err error) {
+	if e.Desc, data, err = readString(data); err != nil {
+		return
+	}
+	count, n := binary.Uvarint(data)
+	// 每个字段至少两个字节
+	if n <= 0 || count > uint64(len(data)-n)/2 {
+		return ErrPacketsizeInvalid
+	}
+	data = data[n:]
+	e.Violations = make([]FieldViolation, count)
+	for k := range e.Violations {
+		if e.Violations[k].Field, data, err = readString(data); err != nil {
+			return
+		}
+		if e.Violations[k].Desc, data, err = readString(data); err != nil {
+			return
+		}
+	}
+	if len(data) > 0 {
+		return ErrPacketsizeInvalid
+	}
+	return
+}
+
+func appendUvarint(data []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendString(data []byte, v string) []byte {
+	return append(appendUvarint(data, uint64(len(v))), v...)
+}
+
+func readString(data []byte) (v string, left []byte, err error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return "", nil, ErrPacketsizeInvalid
+	}
+	data = data[n:]
+	return string(data[:size]), data[size:], nil
+}
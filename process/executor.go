@@ -0,0 +1,205 @@
+package process
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+//go:generate mockgen -source executor.go -destination ../testpkg/mock_process/executor.go
+
+// Executor 消息执行器. Process 在 DispatchPacketFilter 之后调用,把消息处理流程切换到其他协程执行.
+// Dispatch 返回错误表示消息没有被接收,由调用方释放消息; 接收的消息在执行器协程内调用next,
+// Load计数和Context释放都在next内完成.
+type Executor interface {
+	Dispatch(inner *InnerOptions, pkg interface{}, next PacketDispatcherFunc) (err error)
+}
+
+// DispatchKeyFunc 计算消息分发key,相同key的消息顺序执行. inner.BindData 为会话对象.
+type DispatchKeyFunc func(inner *InnerOptions, pkg interface{}) uint64
+
+// SessionKey 按照会话分发,同一个会话的消息顺序执行
+func SessionKey(inner *InnerOptions, pkg interface{}) uint64 {
+	return uint64(reflect.ValueOf(inner).Pointer())
+}
+
+// 执行器错误
+var (
+	ErrExecutorQueueFull = errors.New("executor queue full")
+	ErrExecutorClosed    = errors.New("executor closed")
+)
+
+// ExecutorOption executor options
+//
+//go:generate gogen option -n ExecutorOption -f Executor -o option.executor.go
+func walleExecutor() interface{} {
+	return map[string]interface{}{
+		// KeyFunc 消息分发key
+		"KeyFunc": DispatchKeyFunc(SessionKey),
+		// QueueSize 每个key(MailboxExecutor)或者每个worker(ShardExecutor)的队列长度,队列满时拒绝消息
+		"QueueSize": int(1024),
+		// Workers ShardExecutor worker数量
+		"Workers": int(runtime.NumCPU()),
+		// LockOSThread ShardExecutor worker绑定系统线程
+		"LockOSThread": false,
+	}
+}
+
+type executeTask struct {
+	pkg  interface{}
+	next PacketDispatcherFunc
+}
+
+// MailboxExecutor 每个key一个消息队列,有消息时启动协程顺序执行,队列为空时协程退出.
+// 默认每个会话一个队列(actor模式).
+type MailboxExecutor struct {
+	opts    *ExecutorOptions
+	mux     sync.Mutex
+	boxes   map[uint64]*mailbox
+	pending atomic.Int64
+}
+
+type mailbox struct {
+	queue []executeTask
+}
+
+var _ Executor = (*MailboxExecutor)(nil)
+
+// NewMailboxExecutor new mailbox executor
+func NewMailboxExecutor(opts ...ExecutorOption) *MailboxExecutor {
+	return &MailboxExecutor{
+		opts:  NewExecutorOptions(opts...),
+		boxes: make(map[uint64]*mailbox),
+	}
+}
+
+// Dispatch 消息放入key对应的队列
+func (e *MailboxExecutor) Dispatch(inner *InnerOptions, pkg interface{}, next PacketDispatcherFunc) (err error) {
+	key := e.opts.KeyFunc(inner, pkg)
+	e.mux.Lock()
+	box, ok := e.boxes[key]
+	if !ok {
+		box = &mailbox{}
+		e.boxes[key] = box
+	}
+	if e.opts.QueueSize > 0 && len(box.queue) >= e.opts.QueueSize {
+		e.mux.Unlock()
+		return ErrExecutorQueueFull
+	}
+	box.queue = append(box.queue, executeTask{pkg: pkg, next: next})
+	e.mux.Unlock()
+	e.pending.Inc()
+	if !ok {
+		go e.run(key, box)
+	}
+	return
+}
+
+// Pending 等待执行的消息数量
+func (e *MailboxExecutor) Pending() int64 {
+	return e.pending.Load()
+}
+
+func (e *MailboxExecutor) run(key uint64, box *mailbox) {
+	for {
+		e.mux.Lock()
+		if len(box.queue) == 0 {
+			delete(e.boxes, key)
+			e.mux.Unlock()
+			return
+		}
+		task := box.queue[0]
+		box.queue[0] = executeTask{}
+		box.queue = box.queue[1:]
+		e.mux.Unlock()
+		e.pending.Dec()
+		task.next(task.pkg)
+	}
+}
+
+// ShardExecutor 固定数量的worker,按照key分配消息,相同key的消息顺序执行.
+// 开启LockOSThread时,每个worker绑定一个系统线程.
+type ShardExecutor struct {
+	opts    *ExecutorOptions
+	mux     sync.RWMutex
+	closed  bool
+	workers []chan executeTask
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+var _ Executor = (*ShardExecutor)(nil)
+
+// NewShardExecutor new shard executor, start workers
+func NewShardExecutor(opts ...ExecutorOption) *ShardExecutor {
+	e := &ShardExecutor{
+		opts: NewExecutorOptions(opts...),
+		stop: make(chan struct{}),
+	}
+	if e.opts.Workers < 1 {
+		e.opts.Workers = 1
+	}
+	e.workers = make([]chan executeTask, e.opts.Workers)
+	for k := range e.workers {
+		e.workers[k] = make(chan executeTask, e.opts.QueueSize)
+		e.wg.Add(1)
+		go e.run(e.workers[k])
+	}
+	return e
+}
+
+// Dispatch 消息放入key对应的worker队列
+func (e *ShardExecutor) Dispatch(inner *InnerOptions, pkg interface{}, next PacketDispatcherFunc) (err error) {
+	worker := e.workers[e.opts.KeyFunc(inner, pkg)%uint64(len(e.workers))]
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	if e.closed {
+		return ErrExecutorClosed
+	}
+	select {
+	case worker <- executeTask{pkg: pkg, next: next}:
+		return
+	default:
+		return ErrExecutorQueueFull
+	}
+}
+
+// Close 停止接收消息,等待已接收的消息执行完成
+func (e *ShardExecutor) Close() {
+	e.mux.Lock()
+	if e.closed {
+		e.mux.Unlock()
+		return
+	}
+	e.closed = true
+	e.mux.Unlock()
+	close(e.stop)
+	e.wg.Wait()
+}
+
+func (e *ShardExecutor) run(worker chan executeTask) {
+	defer e.wg.Done()
+	if e.opts.LockOSThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	for {
+		select {
+		case task := <-worker:
+			task.next(task.pkg)
+		case <-e.stop:
+			// 执行剩余消息
+			for {
+				select {
+				case task := <-worker:
+					task.next(task.pkg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package process
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	zaplog "github.com/walleframe/walle/zaplog"
+	zap "go.uber.org/zap"
+)
+
+func TestExecutor_Order(t *testing.T) {
+	shard := NewShardExecutor(
+		WithExecutorOptionWorkers(4),
+		WithExecutorOptionLockOSThread(true),
+		WithExecutorOptionKeyFunc(func(inner *InnerOptions, pkg interface{}) uint64 {
+			return uint64(pkg.([2]int)[0])
+		}),
+	)
+	defer shard.Close()
+	executors := map[string]Executor{
+		"mailbox": NewMailboxExecutor(
+			WithExecutorOptionKeyFunc(func(inner *InnerOptions, pkg interface{}) uint64 {
+				return uint64(pkg.([2]int)[0])
+			}),
+		),
+		"shard": shard,
+	}
+	for name, e := range executors {
+		t.Run(name, func(t *testing.T) {
+			const keys, count = 8, 100
+			mux := sync.Mutex{}
+			result := make(map[int][]int)
+			wg := sync.WaitGroup{}
+			wg.Add(keys * count)
+			next := func(pkg interface{}) error {
+				v := pkg.([2]int)
+				mux.Lock()
+				result[v[0]] = append(result[v[0]], v[1])
+				mux.Unlock()
+				wg.Done()
+				return nil
+			}
+			for i := 0; i < count; i++ {
+				for key := 0; key < keys; key++ {
+					assert.Nil(t, e.Dispatch(nil, [2]int{key, i}, next))
+				}
+			}
+			wg.Wait()
+			for key := 0; key < keys; key++ {
+				assert.Len(t, result[key], count)
+				for i, v := range result[key] {
+					if !assert.Equal(t, i, v, "key %d order", key) {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestExecutor_QueueFull(t *testing.T) {
+	e := NewMailboxExecutor(WithExecutorOptionQueueSize(1))
+	block := make(chan struct{})
+	running := make(chan struct{})
+	done := make(chan struct{})
+	inner := NewInnerOptions()
+	assert.Nil(t, e.Dispatch(inner, 1, func(pkg interface{}) error {
+		close(running)
+		<-block
+		return nil
+	}))
+	<-running
+	assert.Nil(t, e.Dispatch(inner, 2, func(pkg interface{}) error {
+		close(done)
+		return nil
+	}))
+	assert.ErrorIs(t, e.Dispatch(inner, 3, func(pkg interface{}) error { return nil }), ErrExecutorQueueFull)
+	// 其他会话不受影响
+	assert.Nil(t, e.Dispatch(NewInnerOptions(), 4, func(pkg interface{}) error { return nil }))
+	close(block)
+	<-done
+
+	shard := NewShardExecutor()
+	shard.Close()
+	assert.ErrorIs(t, shard.Dispatch(inner, 1, func(pkg interface{}) error { return nil }), ErrExecutorClosed)
+}
+
+func TestProcess_Executor(t *testing.T) {
+	rq := packet.NewTestPacket(packet.CmdRequest, nil, metadata.Pairs())
+	rq.SetURI("kk")
+	data, err := packet.GetCodec().Marshal(rq)
+	assert.Nil(t, err)
+
+	const count = 10
+	wg := sync.WaitGroup{}
+	wg.Add(count)
+	r := &MixRouter{}
+	r.Register("kk", func(ctx Context) {
+		wg.Done()
+	})
+	load := NewInnerOptions().Load
+	p := NewProcess(
+		NewInnerOptions(
+			WithInnerOptionOutput(&bytes.Buffer{}),
+			WithInnerOptionRouter(r),
+			WithInnerOptionLoad(load),
+		),
+		NewProcessOptions(
+			WithLogger(zaplog.NewLogger(zap.NewNop())),
+			WithExecutor(NewMailboxExecutor()),
+			WithLoadLimitFilter(func(req interface{}, count AtomicNumber) bool {
+				count.Inc()
+				return false
+			}),
+		),
+	)
+	for i := 0; i < count; i++ {
+		assert.Nil(t, p.OnRead(data))
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool { return load.Load() == 0 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package process
+
+import "go.uber.org/zap"
+
+// RequestFunc 包装请求处理函数,与wrpc生成代码的处理方式一致:
+// 解析请求失败或者处理函数返回错误时响应错误,否则响应rs(rs为nil时响应空消息).
+func RequestFunc[Rq, Rs any](h func(ctx Context, rq *Rq) (*Rs, error)) RouterFunc {
+	return func(ctx Context) {
+		rq := new(Rq)
+		err := ctx.Bind(rq)
+		if err != nil {
+			ctx.Respond(ctx, err, nil)
+			return
+		}
+		rs, err := h(ctx, rq)
+		if err != nil {
+			ctx.Respond(ctx, err, nil)
+			return
+		}
+		if rs == nil {
+			ctx.Respond(ctx, nil, nil)
+			return
+		}
+		ctx.Respond(ctx, rs, nil)
+	}
+}
+
+// NotifyFunc 包装通知处理函数. 通知消息不响应,解析失败时不调用处理函数.
+// 解析失败和处理函数返回的错误记录日志.
+func NotifyFunc[Rq any](h func(ctx Context, rq *Rq) error) RouterFunc {
+	return func(ctx Context) {
+		rq := new(Rq)
+		err := ctx.Bind(rq)
+		if err != nil {
+			ctx.Logger().New("process.NotifyFunc").Error("bind notify failed", zap.Error(err))
+			return
+		}
+		err = h(ctx, rq)
+		if err != nil {
+			ctx.Logger().New("process.NotifyFunc").Error("handle notify failed", zap.Error(err))
+		}
+	}
+}
+
+// Handle 注册请求处理函数
+//
+//	process.Handle(router, "/add", func(ctx process.Context, rq *AddRq) (*AddRs, error) {
+//		return &AddRs{Sum: rq.A + rq.B}, nil
+//	})
+func Handle[Rq, Rs any](r Router, uri interface{}, h func(ctx Context, rq *Rq) (*Rs, error), m ...MiddlewareFunc) error {
+	return r.Register(uri, RequestFunc(h), m...)
+}
+
+// HandleNotify 注册通知处理函数
+func HandleNotify[Rq any](r Router, uri interface{}, h func(ctx Context, rq *Rq) error, m ...MiddlewareFunc) error {
+	return r.Register(uri, NotifyFunc(h), m...)
+}
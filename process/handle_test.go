@@ -0,0 +1,84 @@
+package process
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/message"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type testAddRq struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+type testAddRs struct {
+	Sum int `json:"sum"`
+}
+
+func TestHandle(t *testing.T) {
+	r := NewTrieRouter()
+	assert.Nil(t, Handle(r, "/add", func(ctx Context, rq *testAddRq) (*testAddRs, error) {
+		if rq.A < 0 {
+			return nil, errcode.ErrNotSupport
+		}
+		if rq.A == 0 {
+			return nil, nil
+		}
+		return &testAddRs{Sum: rq.A + rq.B}, nil
+	}))
+	var notified *testAddRq
+	assert.Nil(t, HandleNotify(r, uint32(10), func(ctx Context, rq *testAddRq) error {
+		notified = rq
+		return errors.New("ignored")
+	}))
+	out := &bytes.Buffer{}
+	core, logs := observer.New(zap.DebugLevel)
+	p := NewProcess(
+		NewInnerOptions(WithInnerOptionRouter(r), WithInnerOptionOutput(out)),
+		NewProcessOptions(WithMsgCodec(message.JSONCodec), WithLogger(zaplog.NewLogger(zap.New(core)))),
+	)
+	call := func(cmd packet.PacketCmd, uri interface{}, payload string) (rsp *packet.Packet) {
+		out.Reset()
+		pkg := packet.NewTestPacket(cmd, []byte(payload), nil)
+		assert.Nil(t, p.Opts.PacketWraper.NewPacket(pkg, cmd, uri, nil))
+		assert.Nil(t, p.innerPacket(pkg))
+		if out.Len() == 0 {
+			return nil
+		}
+		rsp = packet.NewPacket()
+		assert.Nil(t, p.Opts.PacketCodec.Unmarshal(out.Bytes(), rsp))
+		return rsp
+	}
+
+	// 正常响应
+	rs := &testAddRs{}
+	rsp := call(packet.CmdRequest, "/add", `{"a":1,"b":2}`)
+	assert.Nil(t, p.Opts.PacketWraper.PayloadUnmarshal(rsp, p.Opts.MsgCodec, rs))
+	assert.Equal(t, 3, rs.Sum)
+	// 返回错误
+	rsp = call(packet.CmdRequest, "/add", `{"a":-1}`)
+	assert.True(t, rsp.HasFlag(packet.FlagError))
+	assert.True(t, errcode.Is(p.Opts.PacketWraper.PayloadUnmarshal(rsp, p.Opts.MsgCodec, rs), errcode.ErrorCodeNotSupport))
+	// nil响应
+	rsp = call(packet.CmdRequest, "/add", `{"a":0}`)
+	assert.False(t, rsp.HasFlag(packet.FlagError))
+	assert.Empty(t, rsp.Payload())
+	// 解析失败
+	rsp = call(packet.CmdRequest, "/add", `{`)
+	assert.True(t, rsp.HasFlag(packet.FlagError))
+	// 通知不响应
+	assert.Nil(t, call(packet.CmdNotify, uint32(10), `{"a":5}`))
+	assert.Equal(t, &testAddRq{A: 5}, notified)
+	// 通知处理错误和解析失败记录日志
+	assert.Equal(t, 1, logs.FilterMessage("handle notify failed").Len())
+	assert.Nil(t, call(packet.CmdNotify, uint32(10), `{`))
+	assert.Equal(t, 1, logs.FilterMessage("bind notify failed").Len())
+}
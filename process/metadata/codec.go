@@ -3,9 +3,11 @@ package metadata
 import (
 	"encoding/binary"
 	"errors"
+	"math"
 	"net/url"
 	"sort"
 
+	"github.com/walleframe/walle/process/errcode"
 	"github.com/walleframe/walle/util"
 )
 
@@ -31,7 +33,15 @@ func (urlMetaCodec) Unmarshal(data []byte, md MD) (err error) {
 	}
 	val, err := url.ParseQuery(util.BytesToString(data))
 	if err != nil {
-		return err
+		return errcode.WrapError(errcode.ErrInvalidPacket, err)
+	}
+	if len(val) > MaxKeys {
+		return errcode.ErrPacketsizeInvalid
+	}
+	for k, v := range val {
+		if err = checkLimits(k, v); err != nil {
+			return err
+		}
 	}
 	for k, v := range val {
 		md[k] = v
@@ -51,9 +61,16 @@ func (binaryMDCodec) Marshal(v MD) (data []byte, err error) {
 	keys := make([]string, 0, len(v))
 	size := 0
 	for k, v := range v {
+		// 长度使用2字节保存
+		if len(k) > math.MaxUint16 || len(v) > math.MaxUint16 {
+			return nil, errcode.ErrPacketsizeInvalid
+		}
 		keys = append(keys, k)
 		size += 4 + len(k)
 		for _, m := range v {
+			if len(m) > math.MaxUint16 {
+				return nil, errcode.ErrPacketsizeInvalid
+			}
 			size += 2 + len(m)
 		}
 	}
@@ -74,41 +91,73 @@ func (binaryMDCodec) Marshal(v MD) (data []byte, err error) {
 	return
 }
 
+// 2byte key-len 2byte value-count xbyte-key [2byte value-len xbyte-value]...
 func (binaryMDCodec) Unmarshal(data []byte, v MD) (err error) {
 	if len(data) <= 0 {
 		return nil
 	}
-	for len(data) >= 4 {
+	keys := 0
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return ErrInvalidSize
+		}
 		l := int(binary.BigEndian.Uint16(data))
 		size := int(binary.BigEndian.Uint16(data[2:]))
 		if len(data) < 4+l {
-			err = ErrInvalidSize
-			return
+			return ErrInvalidSize
+		}
+		keys++
+		if keys > MaxKeys || l > MaxKeySize || size > MaxValues {
+			return errcode.ErrPacketsizeInvalid
 		}
 		k := string(data[4 : 4+l])
 		data = data[4+l:]
-		v[k] = make([]string, 0, size)
-		for i := 0; i < int(size); i++ {
+		vs := make([]string, 0, size)
+		for i := 0; i < size; i++ {
 			if len(data) < 2 {
-				err = ErrInvalidSize
-				return
+				return ErrInvalidSize
 			}
 			l = int(binary.BigEndian.Uint16(data))
 			if len(data) < 2+l {
-				err = ErrInvalidSize
-				return
+				return ErrInvalidSize
+			}
+			if l > MaxValueSize {
+				return errcode.ErrPacketsizeInvalid
 			}
-			v[k] = append(v[k], string(data[2:2+l]))
+			vs = append(vs, string(data[2:2+l]))
 			data = data[2+l:]
 		}
-	}
-	if len(data) > 0 {
-		return ErrInvalidSize
+		v[k] = vs
 	}
 	return
 }
 
-var ErrInvalidSize = errors.New("invalid size")
+// ErrInvalidSize 元数据格式错误,长度字段超出数据范围. 错误码 errcode.ErrorCodeInvalidPacket
+var ErrInvalidSize = errcode.WrapError(errcode.ErrInvalidPacket, errors.New("invalid metadata size"))
+
+// 元数据解码限制,超过限制返回 errcode.ErrPacketsizeInvalid. 小于等于0表示不允许.
+var (
+	// MaxKeys 最多key数量
+	MaxKeys = 64
+	// MaxKeySize key最大长度
+	MaxKeySize = 256
+	// MaxValues 单个key最多值数量
+	MaxValues = 64
+	// MaxValueSize 值最大长度
+	MaxValueSize = 8192
+)
+
+func checkLimits(k string, vs []string) error {
+	if len(k) > MaxKeySize || len(vs) > MaxValues {
+		return errcode.ErrPacketsizeInvalid
+	}
+	for _, v := range vs {
+		if len(v) > MaxValueSize {
+			return errcode.ErrPacketsizeInvalid
+		}
+	}
+	return nil
+}
 
 var defaultCodec = BinaryCodec
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/errcode"
 )
 
 func TestCodec(t *testing.T) {
@@ -41,3 +42,29 @@ func TestCodec(t *testing.T) {
 	}
 	assert.Nil(t, nil)
 }
+
+func TestCodecLimits(t *testing.T) {
+	maxKeys, maxValues, maxValueSize := MaxKeys, MaxValues, MaxValueSize
+	t.Cleanup(func() {
+		MaxKeys, MaxValues, MaxValueSize = maxKeys, maxValues, maxValueSize
+	})
+	for name, codec := range map[string]Codec{"url": urlMetaCodec{}, "binary": BinaryCodec} {
+		md := MD{"k": {"v1", "v2"}}
+		data, err := codec.Marshal(md)
+		assert.Nil(t, err, name)
+
+		MaxValues = 1
+		assert.Equal(t, errcode.ErrPacketsizeInvalid, codec.Unmarshal(data, MD{}), name)
+		MaxValues = maxValues
+		MaxValueSize = 1
+		assert.Equal(t, errcode.ErrPacketsizeInvalid, codec.Unmarshal(data, MD{}), name)
+		MaxValueSize = maxValueSize
+		MaxKeys = 0
+		assert.Equal(t, errcode.ErrPacketsizeInvalid, codec.Unmarshal(data, MD{}), name)
+		MaxKeys = maxKeys
+	}
+	// 长度字段超出范围
+	assert.Equal(t, ErrInvalidSize, BinaryCodec.Unmarshal([]byte{0, 1, 0, 2, 'k'}, MD{}))
+	assert.Equal(t, ErrInvalidSize, BinaryCodec.Unmarshal([]byte{0, 1, 0, 1, 'k', 0, 5, 'v'}, MD{}))
+	assert.Equal(t, errcode.ErrorCodeInvalidPacket, errcode.ErrorCode(errcode.Code(ErrInvalidSize)))
+}
@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzCodec 任意输入不能panic,解码成功的元数据重新编码之后结果一致
+func fuzzCodec(f *testing.F, codec Codec) {
+	for _, md := range []MD{Pairs("k", "v"), {"k": {"v1", "v2"}, "x-timeout": {"100"}}} {
+		data, err := codec.Marshal(md)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(append([]byte(nil), data...))
+	}
+	f.Add([]byte{0, 1, 0xff, 0xff, 'k'})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		md := MD{}
+		if err := codec.Unmarshal(data, md); err != nil {
+			return
+		}
+		out, err := codec.Marshal(md)
+		if err != nil {
+			return
+		}
+		nmd := MD{}
+		assert.Nil(t, codec.Unmarshal(out, nmd), "unmarshal marshaled metadata")
+		assert.Equal(t, len(md), len(nmd), "marshal again")
+		for k, v := range md {
+			assert.Equal(t, len(v), len(nmd[k]), k)
+		}
+	})
+}
+
+func FuzzBinaryCodec(f *testing.F) {
+	fuzzCodec(f, BinaryCodec)
+}
+
+func FuzzURLCodec(f *testing.F) {
+	fuzzCodec(f, urlMetaCodec{})
+}
@@ -0,0 +1,32 @@
+package metadata
+
+import (
+	"strconv"
+	"time"
+)
+
+// TimeoutKey 调用方剩余等待时间(毫秒). 使用剩余时间而不是截止时间,不受服务器时钟差异影响.
+const TimeoutKey = "x-timeout"
+
+// WithTimeout 返回设置了剩余等待时间的新metadata,不修改md.
+func WithTimeout(md MD, timeout time.Duration) MD {
+	out := make(MD, len(md)+1)
+	for k, v := range md {
+		out[k] = v
+	}
+	ms := timeout.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	out[TimeoutKey] = []string{strconv.FormatInt(ms, 10)}
+	return out
+}
+
+// Timeout 获取调用方剩余等待时间
+func (md MD) Timeout() (timeout time.Duration, ok bool) {
+	ms, ok := md.GetFirstInt(TimeoutKey)
+	if !ok || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
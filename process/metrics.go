@@ -0,0 +1,132 @@
+package process
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/util/metrics"
+)
+
+// 处理流程监控指标. ProcessOptions.Metrics 开启
+var (
+	metricRequests = metrics.NewCounter("walle_process_requests_total",
+		"handled request and notify packets", "route", "cmd")
+	metricRequestErrors = metrics.NewCounter("walle_process_request_errors_total",
+		"requests responded with error", "route", "code")
+	metricRequestDuration = metrics.NewHistogram("walle_process_request_duration_seconds",
+		"handler chain latency", nil, "route")
+	metricDispatchErrors = metrics.NewCounter("walle_process_dispatch_errors_total",
+		"packets dropped before calling handlers", "reason")
+
+	metricUnmarshalErrors = metricDispatchErrors.With("unmarshal")
+	metricNoRouterErrors  = metricDispatchErrors.With("no_router")
+	metricLoadLimitErrors = metricDispatchErrors.With("load_limit")
+)
+
+// 未注册路由(NoRouter)和未实现 routeStatRouter 接口的路由使用固定标签
+var (
+	noRouterStat     = &routeStat{route: "norouter"}
+	unknownRouteStat = &routeStat{route: "unknown"}
+)
+
+// routeStatRouter 路由返回匹配节点的监控指标
+type routeStatRouter interface {
+	getHandlersStat(in interface{}) (handlers []RouterFunc, params Params, stat *routeStat, err error)
+}
+
+// routeStat 路由节点的监控指标. 标签使用注册时的路由模式或者消息ID,
+// 指标在第一次使用时获取并缓存,处理请求时不再查找标签.
+type routeStat struct {
+	route    string
+	requests [packet.CmdStreamReply + 1]atomic.Value // *metrics.Counter
+	duration atomic.Value                            // *metrics.Histogram
+}
+
+// newRouteStat 路径使用注册时的模式(/room/:roomId/chat),消息ID使用 "msgid:{id}"
+func newRouteStat(uri interface{}) *routeStat {
+	switch v := uri.(type) {
+	case string:
+		return &routeStat{route: v}
+	case uint32:
+		return &routeStat{route: "msgid:" + strconv.FormatUint(uint64(v), 10)}
+	case int:
+		return &routeStat{route: "msgid:" + strconv.Itoa(v)}
+	}
+	return unknownRouteStat
+}
+
+func (s *routeStat) counter(cmd packet.PacketCmd) *metrics.Counter {
+	if int(cmd) >= len(s.requests) {
+		return metricRequests.With(s.route, cmdLabel(cmd))
+	}
+	if c, ok := s.requests[cmd].Load().(*metrics.Counter); ok {
+		return c
+	}
+	c := metricRequests.With(s.route, cmdLabel(cmd))
+	s.requests[cmd].Store(c)
+	return c
+}
+
+func (s *routeStat) histogram() *metrics.Histogram {
+	if h, ok := s.duration.Load().(*metrics.Histogram); ok {
+		return h
+	}
+	h := metricRequestDuration.With(s.route)
+	s.duration.Store(h)
+	return h
+}
+
+// begin 请求包在 ctx.Next 之后可能已经回收,需要在调用之前记录
+func (s *routeStat) begin(pkg interface{}) *requestStat {
+	p, ok := pkg.(*packet.Packet)
+	if !ok || s == nil {
+		return nil
+	}
+	s.counter(p.Cmd()).Inc()
+	return &requestStat{
+		stat:  s,
+		start: time.Now(),
+	}
+}
+
+// requestStat 记录一次请求的监控指标
+type requestStat struct {
+	stat  *routeStat
+	start time.Time
+	err   error
+}
+
+func (rs *requestStat) hook(body interface{}, md metadata.MD) metadata.MD {
+	if e, ok := body.(error); ok {
+		rs.err = e
+	}
+	return md
+}
+
+func (rs *requestStat) done() {
+	if rs == nil {
+		return
+	}
+	rs.stat.histogram().ObserveDuration(time.Since(rs.start))
+	if rs.err != nil {
+		metricRequestErrors.With(rs.stat.route, strconv.FormatUint(uint64(errcode.Code(rs.err)), 10)).Inc()
+	}
+}
+
+func cmdLabel(cmd packet.PacketCmd) string {
+	switch cmd {
+	case packet.CmdNotify:
+		return "notify"
+	case packet.CmdRequest:
+		return "request"
+	case packet.CmdResponse:
+		return "response"
+	case packet.CmdStream, packet.CmdStreamReply:
+		return "stream"
+	}
+	return strconv.Itoa(int(cmd))
+}
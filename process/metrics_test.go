@@ -0,0 +1,105 @@
+package process
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/message"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+func TestRouteStat(t *testing.T) {
+	f := func(ctx Context) {}
+	cow := NewCOWRouter()
+	for _, r := range []Router{NewTrieRouter(), &MixRouter{}, cow, NewTrieRouter().Group("/v1")} {
+		assert.Nil(t, r.Register("/login", f))
+		assert.Nil(t, r.Register(12, f))
+		label := func(uri string, msgID uint32) string {
+			pkg := packet.NewPacket()
+			pkg.SetURI(uri)
+			pkg.SetMsgID(msgID)
+			_, _, stat, err := r.(routeStatRouter).getHandlersStat(pkg)
+			assert.Nil(t, err, uri)
+			return stat.route
+		}
+		prefix := ""
+		if _, ok := r.(*routerGroup); ok {
+			prefix = "/v1"
+		}
+		assert.Equal(t, prefix+"/login", label(prefix+"/login", 0))
+		assert.Equal(t, "msgid:12", label("", 12))
+		// 未注册的路由使用固定标签
+		assert.Nil(t, r.NoRouter(f))
+		assert.Equal(t, "norouter", label("/not_found/1", 0))
+		assert.Equal(t, "norouter", label("/not_found/2", 0))
+	}
+	// 路径参数使用注册时的模式
+	r := NewTrieRouter()
+	for _, path := range []string{"/room/:roomId/chat", "/static/*path"} {
+		assert.Nil(t, r.Register(path, f), path)
+	}
+	for uri, want := range map[string]string{
+		"/room/10/chat":    "/room/:roomId/chat",
+		"/room/room/chat":  "/room/:roomId/chat",
+		"/static/a/b/c.js": "/static/*path",
+	} {
+		pkg := packet.NewPacket()
+		pkg.SetURI(uri)
+		_, _, stat, err := r.getHandlersStat(pkg)
+		assert.Nil(t, err, uri)
+		assert.Equal(t, want, stat.route, uri)
+	}
+	// 替换路由保留标签
+	assert.Nil(t, cow.Replace("/login", f))
+	pkg := packet.NewPacket()
+	pkg.SetURI("/login")
+	_, _, stat, _ := cow.getHandlersStat(pkg)
+	assert.Equal(t, "/login", stat.route)
+}
+
+func TestProcessMetrics(t *testing.T) {
+	r := NewTrieRouter()
+	r.Register("/metrics/:id", func(ctx Context) {
+		ctx.Respond(ctx, errcode.ErrTooManyRequests, nil)
+	})
+	p := NewProcess(
+		NewInnerOptions(
+			WithInnerOptionOutput(&bytes.Buffer{}),
+			WithInnerOptionRouter(r),
+		),
+		NewProcessOptions(
+			WithLogger(zaplog.NewLogger(zap.NewNop())),
+			WithFrameLogger(zaplog.NewLogger(zap.NewNop())),
+			WithMsgCodec(message.JSONCodec),
+			WithMetrics(true),
+		),
+	)
+	requests := metricRequests.With("/metrics/:id", "request").Value()
+	errs := metricRequestErrors.With("/metrics/:id", "11").Value()
+	count := metricRequestDuration.With("/metrics/:id").Count()
+	noRouter := metricDispatchErrors.With("no_router").Value()
+	for _, uri := range []string{"/metrics/1", "/metrics/2", "/not_found"} {
+		rq := packet.NewTestPacket(packet.CmdRequest, []byte("{}"), nil)
+		rq.SetURI(uri)
+		data, err := packet.GetCodec().Marshal(rq)
+		assert.Nil(t, err)
+		p.OnRead(data)
+	}
+	assert.Equal(t, requests+2, metricRequests.With("/metrics/:id", "request").Value())
+	assert.Equal(t, errs+2, metricRequestErrors.With("/metrics/:id", "11").Value())
+	assert.Equal(t, count+2, metricRequestDuration.With("/metrics/:id").Count())
+	assert.Equal(t, noRouter+1, metricDispatchErrors.With("no_router").Value())
+
+	// 默认不记录
+	p.Opts.Metrics = false
+	rq := packet.NewTestPacket(packet.CmdRequest, []byte("{}"), nil)
+	rq.SetURI("/metrics/3")
+	data, err := packet.GetCodec().Marshal(rq)
+	assert.Nil(t, err)
+	p.OnRead(data)
+	assert.Equal(t, requests+2, metricRequests.With("/metrics/:id", "request").Value())
+}
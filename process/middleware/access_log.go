@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/walleframe/walle/process"
+	"go.uber.org/zap"
+)
+
+// AccessLog 访问日志. 记录路由,耗时和错误码(通过 Context.Respond 返回的错误).
+func AccessLog() process.MiddlewareFunc {
+	return func(ctx process.Context) {
+		req := getRequest(ctx)
+		log := getLogger(ctx)
+		rec := &respondRecorder{}
+		process.AddRespondHook(ctx, rec.hook)
+		start := time.Now()
+		ctx.Next(ctx)
+		log.New("middleware.AccessLog").Info("access",
+			zap.String("uri", req.uri),
+			zap.Uint32("msgid", req.msgID),
+			zap.Duration("latency", time.Since(start)),
+			zap.Bool("responded", rec.responded),
+			zap.Uint32("code", ErrorCode(rec.err)),
+			zap.Error(rec.err),
+		)
+	}
+}
+
+// SlowRequest 慢请求告警. 处理耗时超过阈值时记录Warn日志.
+// thresholds 单独设置路由的阈值,key与 Router.Register 的uri一致(string或者消息ID).
+func SlowRequest(threshold time.Duration, thresholds map[interface{}]time.Duration) process.MiddlewareFunc {
+	limits := make(map[interface{}]time.Duration, len(thresholds))
+	for k, v := range thresholds {
+		if id, ok := k.(int); ok {
+			k = uint32(id)
+		}
+		limits[k] = v
+	}
+	return func(ctx process.Context) {
+		req := getRequest(ctx)
+		log := getLogger(ctx)
+		limit := threshold
+		if len(limits) > 0 {
+			if v, ok := limits[req.routeKey()]; ok {
+				limit = v
+			}
+		}
+		start := time.Now()
+		ctx.Next(ctx)
+		if cost := time.Since(start); limit > 0 && cost > limit {
+			log.New("middleware.SlowRequest").Warn("slow request",
+				zap.String("uri", req.uri),
+				zap.Uint32("msgid", req.msgID),
+				zap.Duration("latency", cost),
+				zap.Duration("threshold", limit),
+			)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package middleware 常用的路由中间件: 异常恢复,访问日志,慢请求告警,请求ID.
+//
+// 中间件在 ctx.Next 返回之后,请求包和Context可能已经回收,需要的信息在调用 ctx.Next 之前获取.
+package middleware
+
+import (
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/zaplog"
+)
+
+// request 请求信息
+type request struct {
+	uri   string
+	msgID uint32
+	cmd   packet.PacketCmd
+}
+
+func getRequest(ctx process.Context) (req request) {
+	if pkg, ok := ctx.GetRequestPacket().(*packet.Packet); ok {
+		req.uri = pkg.URI()
+		req.msgID = pkg.MsgID()
+		req.cmd = pkg.Cmd()
+	}
+	return
+}
+
+// routeKey 路由key,与 Router.Register 一致
+func (req request) routeKey() interface{} {
+	if req.msgID > 0 {
+		return req.msgID
+	}
+	return req.uri
+}
+
+// getLogger 获取日志接口
+func getLogger(ctx process.Context) *zaplog.Logger {
+	if log := ctx.Logger(); log != nil {
+		return log
+	}
+	return zaplog.GetLogicLogger()
+}
+
+// respondRecorder 记录处理结果
+type respondRecorder struct {
+	responded bool
+	err       error
+}
+
+func (rec *respondRecorder) hook(body interface{}, md metadata.MD) metadata.MD {
+	rec.responded = true
+	if e, ok := body.(error); ok {
+		rec.err = e
+	}
+	return md
+}
+
+// ErrorCode 获取错误码. 未实现错误码接口的错误返回 errcode.ErrorCodeUnkwon
+func ErrorCode(err error) uint32 {
+	return errcode.Code(err)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type testProcess struct {
+	proc process.Process
+	out  *bytes.Buffer
+	logs *observer.ObservedLogs
+}
+
+func newTestProcess(r process.Router) *testProcess {
+	core, logs := observer.New(zap.DebugLevel)
+	tp := &testProcess{out: &bytes.Buffer{}, logs: logs}
+	tp.proc = process.NewProcess(
+		process.NewInnerOptions(
+			process.WithInnerOptionRouter(r),
+			process.WithInnerOptionOutput(tp.out),
+		),
+		process.NewProcessOptions(
+			process.WithLogger(zaplog.NewLogger(zap.New(core))),
+			process.WithLoadLimitFilter(func(req interface{}, count process.AtomicNumber) bool {
+				count.Inc()
+				return false
+			}),
+		),
+	)
+	return tp
+}
+
+// call 发送请求,返回响应包
+func (tp *testProcess) call(t *testing.T, cmd packet.PacketCmd, uri string, md metadata.MD) *packet.Packet {
+	req := packet.NewPacket()
+	req.SetCmd(cmd)
+	req.SetURI(uri)
+	req.SetMD(md)
+	data, err := tp.proc.Opts.PacketCodec.Marshal(req)
+	assert.Nil(t, err)
+	tp.out.Reset()
+	assert.Nil(t, tp.proc.OnRead(data))
+	if tp.out.Len() == 0 {
+		return nil
+	}
+	rsp := packet.NewPacket()
+	assert.Nil(t, tp.proc.Opts.PacketCodec.Unmarshal(tp.out.Bytes(), rsp))
+	return rsp
+}
+
+func TestRecovery(t *testing.T) {
+	r := &process.MixRouter{}
+	r.Use(Recovery(), AccessLog())
+	r.Register("/panic", func(ctx process.Context) {
+		panic("boom")
+	})
+	r.Register("/ok", func(ctx process.Context) {
+		ctx.Respond(ctx, errcode.ErrTimeout, nil)
+	})
+	tp := newTestProcess(r)
+
+	rsp := tp.call(t, packet.CmdRequest, "/panic", nil)
+	if assert.NotNil(t, rsp) {
+		assert.True(t, rsp.HasFlag(packet.FlagError))
+		err := tp.proc.Opts.PacketWraper.PayloadUnmarshal(rsp, tp.proc.Opts.MsgCodec, nil)
+		assert.True(t, errcode.Is(err, errcode.ErrorCodeHandlerPanic))
+	}
+	assert.Equal(t, int64(0), tp.proc.Inner.Load.Load())
+	assert.Equal(t, 1, tp.logs.FilterMessage("handler panic").Len())
+	// notify 不响应
+	assert.Nil(t, tp.call(t, packet.CmdNotify, "/panic", nil))
+
+	// 访问日志记录错误码
+	tp.call(t, packet.CmdRequest, "/ok", nil)
+	access := tp.logs.FilterMessage("access").FilterField(zap.String("uri", "/ok")).All()
+	if assert.Len(t, access, 1) {
+		assert.EqualValues(t, errcode.ErrorCodeTimeout, access[0].ContextMap()["code"])
+	}
+}
+
+func TestSlowRequest(t *testing.T) {
+	r := &process.MixRouter{}
+	r.Use(SlowRequest(time.Hour, map[interface{}]time.Duration{"/slow": time.Millisecond}))
+	handler := func(ctx process.Context) { time.Sleep(5 * time.Millisecond) }
+	r.Register("/slow", handler)
+	r.Register("/fast", handler)
+	tp := newTestProcess(r)
+	tp.call(t, packet.CmdNotify, "/slow", nil)
+	tp.call(t, packet.CmdNotify, "/fast", nil)
+	slow := tp.logs.FilterMessage("slow request").All()
+	if assert.Len(t, slow, 1) {
+		assert.Equal(t, "/slow", slow[0].ContextMap()["uri"])
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	r := &process.MixRouter{}
+	r.Use(RequestID())
+	var id string
+	var out metadata.MD
+	r.Register("/echo", func(ctx process.Context) {
+		// 中间件不包装Context
+		_, ok := ctx.(*process.WrapContext)
+		assert.True(t, ok)
+		id = RequestIDFromContext(ctx)
+		out = OutgoingMD(ctx, metadata.Pairs("k", "v"))
+		ctx.Respond(ctx, nil, nil)
+	})
+	tp := newTestProcess(r)
+
+	// 使用请求中的ID
+	rsp := tp.call(t, packet.CmdRequest, "/echo", metadata.Pairs(RequestIDKey, "abc"))
+	assert.Equal(t, "abc", id)
+	assert.Equal(t, []string{"abc"}, out.Get(RequestIDKey))
+	assert.Equal(t, []string{"v"}, out.Get("k"))
+	if assert.NotNil(t, rsp) {
+		assert.Equal(t, []string{"abc"}, rsp.GetMD().Get(RequestIDKey))
+	}
+
+	// 生成新ID
+	rsp = tp.call(t, packet.CmdRequest, "/echo", nil)
+	assert.NotEmpty(t, id)
+	assert.NotEqual(t, "abc", id)
+	if assert.NotNil(t, rsp) {
+		assert.Equal(t, []string{id}, rsp.GetMD().Get(RequestIDKey))
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"runtime/debug"
+
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
+	"go.uber.org/zap"
+)
+
+// Recovery 捕获后续处理函数的panic,记录堆栈. 请求消息返回 errcode.ErrHandlerPanic.
+// 需要放在所有中间件之前.
+func Recovery() process.MiddlewareFunc {
+	return RecoveryWithError(errcode.ErrHandlerPanic)
+}
+
+// RecoveryWithError 捕获后续处理函数的panic,请求消息返回指定错误
+func RecoveryWithError(rspErr error) process.MiddlewareFunc {
+	return func(ctx process.Context) {
+		req := getRequest(ctx)
+		log := getLogger(ctx)
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			log.New("middleware.Recovery").Error("handler panic",
+				zap.String("uri", req.uri),
+				zap.Uint32("msgid", req.msgID),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			// 请求包已经回收(panic发生在处理完成之后),不再响应
+			if ctx.GetRequestPacket() == nil {
+				return
+			}
+			if req.cmd == packet.CmdRequest {
+				if err := ctx.Respond(ctx, rspErr, nil); err != nil {
+					log.New("middleware.Recovery").Error("respond failed", zap.Error(err))
+				}
+			}
+			// 终止调用链,回收请求包和Context
+			ctx.Abort()
+			ctx.Next(ctx)
+		}()
+		ctx.Next(ctx)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/metadata"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// RequestIDKey 请求ID的metadata key
+const RequestIDKey = "x-request-id"
+
+type requestIDCtxKey struct{}
+
+var (
+	requestIDPrefix string
+	requestIDSeq    atomic.Uint64
+)
+
+func init() {
+	buf := make([]byte, 6)
+	rand.Read(buf)
+	requestIDPrefix = hex.EncodeToString(buf) + "-"
+}
+
+// NewRequestID 生成请求ID. 默认为进程随机前缀加自增序号,可以替换.
+var NewRequestID = func() string {
+	return requestIDPrefix + strconv.FormatUint(requestIDSeq.Inc(), 10)
+}
+
+// RequestID 请求ID. 优先使用请求metadata中的 x-request-id,没有时生成新的ID.
+// 请求ID保存在Context中( RequestIDFromContext ),添加到日志字段,并且随响应metadata返回.
+func RequestID() process.MiddlewareFunc {
+	return func(ctx process.Context) {
+		var id string
+		if md, err := ctx.GetReqeustMD(); err == nil {
+			id, _ = md.GetFirstString(RequestIDKey)
+		}
+		if id == "" {
+			id = NewRequestID()
+		}
+		ctx.WithValue(requestIDCtxKey{}, id)
+		ctx.WithLogFields(zap.String("request_id", id))
+		process.AddRespondHook(ctx, func(body interface{}, md metadata.MD) metadata.MD {
+			return metadata.Join(md, metadata.Pairs(RequestIDKey, id))
+		})
+		ctx.Next(ctx)
+	}
+}
+
+// RequestIDFromContext 获取请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// OutgoingMD 请求其他服务时传递请求ID. 返回新的metadata,不修改md.
+func OutgoingMD(ctx context.Context, md metadata.MD) metadata.MD {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return md
+	}
+	return metadata.Join(md, metadata.Pairs(RequestIDKey, id))
+}
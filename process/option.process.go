@@ -32,6 +32,12 @@ type ProcessOptions struct {
 	DispatchDataFilter DataDispatcherFilter
 	// dispatch packet struct filter
 	DispatchPacketFilter PacketDispatcherFilter
+	// packet executor. nil means process packet in read goroutine.
+	Executor Executor
+	// bind validator. nil means Bind not validate message. use ValidateMessage to enable.
+	Validator func(msg interface{}) error
+	// dispatch metrics. record request count,errors and latency by route(util/metrics).
+	Metrics bool
 	// load limit. return true to ignore packet.
 	LoadLimitFilter func(req interface{}, count AtomicNumber) bool
 }
@@ -117,6 +123,33 @@ func WithDispatchPacketFilter(v PacketDispatcherFilter) ProcessOption {
 	}
 }
 
+// packet executor. nil means process packet in read goroutine.
+func WithExecutor(v Executor) ProcessOption {
+	return func(cc *ProcessOptions) ProcessOption {
+		previous := cc.Executor
+		cc.Executor = v
+		return WithExecutor(previous)
+	}
+}
+
+// bind validator. nil means Bind not validate message. use ValidateMessage to enable.
+func WithValidator(v func(msg interface{}) error) ProcessOption {
+	return func(cc *ProcessOptions) ProcessOption {
+		previous := cc.Validator
+		cc.Validator = v
+		return WithValidator(previous)
+	}
+}
+
+// dispatch metrics. record request count,errors and latency by route(util/metrics).
+func WithMetrics(v bool) ProcessOption {
+	return func(cc *ProcessOptions) ProcessOption {
+		previous := cc.Metrics
+		cc.Metrics = v
+		return WithMetrics(previous)
+	}
+}
+
 // load limit. return true to ignore packet.
 func WithLoadLimitFilter(v func(req interface{}, count AtomicNumber) bool) ProcessOption {
 	return func(cc *ProcessOptions) ProcessOption {
@@ -177,6 +210,9 @@ func newDefaultProcessOptions() *ProcessOptions {
 		MsgCodec:             message.WalleCodec,
 		DispatchDataFilter:   DefaultDataFilter,
 		DispatchPacketFilter: DefaultPacketFilter,
+		Executor:             nil,
+		Validator:            nil,
+		Metrics:              false,
 		LoadLimitFilter: func(req interface{}, count AtomicNumber) bool {
 			return false
 		},
@@ -55,6 +55,12 @@ func walleProcessOption() interface{} {
 		"DispatchDataFilter": DataDispatcherFilter(DefaultDataFilter),
 		// dispatch packet struct filter
 		"DispatchPacketFilter": PacketDispatcherFilter(DefaultPacketFilter),
+		// packet executor. nil means process packet in read goroutine.
+		"Executor": Executor(nil),
+		// bind validator. nil means Bind not validate message. use ValidateMessage to enable.
+		"Validator": (func(msg interface{}) error)(nil),
+		// dispatch metrics. record request count,errors and latency by route(util/metrics).
+		"Metrics": false,
 		// load limit. return true to ignore packet.
 		"LoadLimitFilter": func(req interface{}, count AtomicNumber) bool {
 			return false
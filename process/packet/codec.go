@@ -2,6 +2,7 @@ package packet
 
 import (
 	"encoding/binary"
+	"math"
 
 	"github.com/walleframe/walle/process/errcode"
 	"github.com/walleframe/walle/process/metadata"
@@ -18,6 +19,15 @@ func SetPacketCodec(codec Codec) {
 	defaultPacketCodec = codec
 }
 
+// 网络包解码限制,超过限制返回 errcode.ErrPacketsizeInvalid. 格式错误返回 errcode.ErrInvalidPacket.
+// 元数据限制参考 metadata.MaxKeys 等.
+var (
+	// MaxURILength URI最大长度,只用于 CompactCodec. BytesURICodec 使用1字节存储长度,格式本身限制最长255
+	MaxURILength = 1024
+	// MaxPayloadSize payload最大长度
+	MaxPayloadSize = 64 << 20
+)
+
 // 1byte cmd 1byte flag 1byte reserved 1byte msg-len 8byte sessionid 4byte payload-size xbyte-uri  xbyte-payload xbyte-metadata
 type codecURI struct{}
 
@@ -28,6 +38,10 @@ func (codecURI) Marshal(p interface{}) ([]byte, error) {
 	if !ok {
 		return nil, errcode.ErrUnexpectedCode
 	}
+	// msg-len 1byte
+	if len(pkg.msgURI) > math.MaxUint8 {
+		return nil, errcode.ErrPacketsizeInvalid
+	}
 	md, err := metadata.GetCodec().Marshal(pkg.metadata)
 	if err != nil {
 		return nil, err
@@ -65,8 +79,8 @@ func (codecURI) Marshal(p interface{}) ([]byte, error) {
 	return buf, nil
 }
 func (codecURI) Unmarshal(data []byte, p interface{}) error {
-	if len(data) < 16 {
-		return errcode.ErrPacketsizeInvalid
+	if len(data) < 4+16 {
+		return errcode.ErrInvalidPacket
 	}
 	pkg, ok := p.(*Packet)
 	if !ok {
@@ -81,8 +95,16 @@ func (codecURI) Unmarshal(data []byte, p interface{}) error {
 	pkg.reservd = data[2]
 	pkg.msgLen = data[3]
 	pkg.sessionID = binary.BigEndian.Uint64(data[4:])
-	payloadSize := int(binary.BigEndian.Uint32(data[12:]))
-	idx := int(16 + pkg.msgLen)
+	// 先比较长度再转换为int,32位平台int可能溢出
+	size := binary.BigEndian.Uint32(data[12:])
+	idx := 16 + int(pkg.msgLen)
+	if idx > len(data) || uint64(size) > uint64(len(data)-idx) {
+		return errcode.ErrInvalidPacket
+	}
+	payloadSize := int(size)
+	if payloadSize > MaxPayloadSize {
+		return errcode.ErrPacketsizeInvalid
+	}
 	pkg.msgURI = string(data[16:idx])
 	if payloadSize > cap(pkg.cache) {
 		pkg.cache = make([]byte, payloadSize) //mempool.Pool().Alloc(payloadSize)
@@ -133,8 +155,8 @@ func (codecMID) Marshal(p interface{}) ([]byte, error) {
 	return buf, nil
 }
 func (codecMID) Unmarshal(data []byte, p interface{}) error {
-	if len(data) < 20 {
-		return errcode.ErrPacketsizeInvalid
+	if len(data) < 4+20 {
+		return errcode.ErrInvalidPacket
 	}
 	pkg, ok := p.(*Packet)
 	if !ok {
@@ -149,7 +171,15 @@ func (codecMID) Unmarshal(data []byte, p interface{}) error {
 	pkg.reservd = data[2]
 	//pkg.msgLen = data[3]
 	pkg.sessionID = binary.BigEndian.Uint64(data[4:])
-	payloadSize := int(binary.BigEndian.Uint32(data[12:]))
+	// 先比较长度再转换为int,32位平台int可能溢出
+	size := binary.BigEndian.Uint32(data[12:])
+	if uint64(size) > uint64(len(data)-20) {
+		return errcode.ErrInvalidPacket
+	}
+	payloadSize := int(size)
+	if payloadSize > MaxPayloadSize {
+		return errcode.ErrPacketsizeInvalid
+	}
 	pkg.msgID = binary.BigEndian.Uint32(data[16:])
 	if payloadSize > cap(pkg.cache) {
 		pkg.cache = make([]byte, payloadSize) // mempool.Pool().Alloc(payloadSize)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/errcode"
 	"github.com/walleframe/walle/process/metadata"
 )
 
@@ -73,3 +74,206 @@ func TestBytesMIDCodec(t *testing.T) {
 	}
 
 }
+
+func TestCompactCodec(t *testing.T) {
+	datas := []func(p *Packet){
+		func(p *Packet) {
+		},
+		func(p *Packet) {
+			p.cmd = CmdRequest
+			p.flag = FlagError
+			p.reservd = 0x12
+			p.sessionID = 1<<64 - 1
+			p.msgID = 1<<32 - 1
+			p.msgURI = "/room/10/chat"
+			p.payload = []byte("xxxxxxxxxxxxxxxxxx")
+			p.metadata["x"] = []string{"b"}
+		},
+		func(p *Packet) {
+			p.msgID = 5654
+			p.msgURI = string(make([]byte, 300))
+			p.payload = []byte("x")
+		},
+		func(p *Packet) {
+			p.sessionID = 100
+			p.metadata["x"] = []string{"b", "c"}
+		},
+	}
+	for k, f := range datas {
+		t.Run(fmt.Sprint(k), func(t *testing.T) {
+			p := NewPacket()
+			f(p)
+			data, err := CompactCodec.Marshal(p)
+			assert.Nil(t, err, "marshal result")
+			assert.EqualValues(t, len(data)-4, binary.BigEndian.Uint32(data), "size")
+			np := NewPacket()
+			// 复用的包,不存在的字段需要清空
+			np.msgID, np.msgURI, np.sessionID, np.reservd = 1, "old", 2, 3
+			err = CompactCodec.Unmarshal(data, np)
+			assert.Nil(t, err, "unmarshal result")
+			if len(p.payload) == 0 {
+				p.payload = np.payload
+			}
+			np.CleanForTest()
+			p.CleanForTest()
+			assert.EqualValues(t, p, np, "compare source packet")
+		})
+	}
+
+	// 转发: payload 引用 cache
+	p := NewPacket()
+	p.msgID = 10
+	p.payload = []byte("payload")
+	data, _ := CompactCodec.Marshal(p)
+	np := NewPacket()
+	assert.Nil(t, CompactCodec.Unmarshal(append([]byte(nil), data...), np))
+	data2, err := CompactCodec.Marshal(np)
+	assert.Nil(t, err)
+	assert.Equal(t, data, data2, "marshal unmarshaled packet")
+}
+
+func TestCompactCodecInvalid(t *testing.T) {
+	p := NewPacket()
+	p.msgID = 300
+	p.msgURI = "uri"
+	p.payload = []byte("payload")
+	p.metadata["x"] = []string{"b"}
+	data, err := CompactCodec.Marshal(p)
+	assert.Nil(t, err)
+
+	datas := map[string][]byte{
+		"short":    data[:6],
+		"size":     append(append([]byte(nil), data...), 0),
+		"fields":   {0, 0, 0, 3, 0, 0, 0x80},
+		"varint":   {0, 0, 0, 4, 0, 0, byte(compactMsgID), 0x80},
+		"uri size": {0, 0, 0, 5, 0, 0, byte(compactURI), 0x10, 'a'},
+		"reserved": {0, 0, 0, 3, 0, 0, byte(compactReserved)},
+		"msgid":    {0, 0, 0, 8, 0, 0, byte(compactMsgID), 0x80, 0x80, 0x80, 0x80, 0x10},
+	}
+	for name, data := range datas {
+		assert.NotNil(t, CompactCodec.Unmarshal(data, NewPacket()), name)
+	}
+}
+
+func benchmarkPackets() map[string]func() *Packet {
+	payload := make([]byte, 64)
+	return map[string]func() *Packet{
+		"uri": func() *Packet {
+			p := NewPacket()
+			p.cmd = CmdRequest
+			p.sessionID = 1024
+			p.msgURI = "/room/chat"
+			p.payload = payload
+			return p
+		},
+		"id": func() *Packet {
+			p := NewPacket()
+			p.cmd = CmdRequest
+			p.sessionID = 1024
+			p.msgID = 1001
+			p.payload = payload
+			return p
+		},
+		"md": func() *Packet {
+			p := NewPacket()
+			p.cmd = CmdRequest
+			p.sessionID = 1024
+			p.msgID = 1001
+			p.msgURI = "/room/chat"
+			p.payload = payload
+			p.metadata["traceparent"] = []string{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+			return p
+		},
+	}
+}
+
+var benchmarkCodecs = map[string]Codec{
+	"URI":     BytesURICodec,
+	"MID":     BytesMIDCodec,
+	"Compact": CompactCodec,
+}
+
+func BenchmarkCodecMarshal(b *testing.B) {
+	for pname, newPacket := range benchmarkPackets() {
+		for cname, codec := range benchmarkCodecs {
+			b.Run(cname+"/"+pname, func(b *testing.B) {
+				p := newPacket()
+				b.ReportAllocs()
+				var data []byte
+				for k := 0; k < b.N; k++ {
+					data, _ = codec.Marshal(p)
+				}
+				// 包头(不包含payload)大小
+				b.ReportMetric(float64(len(data)-len(p.payload)), "head-bytes")
+			})
+		}
+	}
+}
+
+func BenchmarkCodecUnmarshal(b *testing.B) {
+	for pname, newPacket := range benchmarkPackets() {
+		for cname, codec := range benchmarkCodecs {
+			b.Run(cname+"/"+pname, func(b *testing.B) {
+				p := newPacket()
+				data, _ := codec.Marshal(p)
+				data = append([]byte(nil), data...)
+				np := NewPacket()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for k := 0; k < b.N; k++ {
+					codec.Unmarshal(data, np)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecLimits(t *testing.T) {
+	maxURILength, maxPayloadSize := MaxURILength, MaxPayloadSize
+	t.Cleanup(func() {
+		MaxURILength, MaxPayloadSize = maxURILength, maxPayloadSize
+	})
+	p := NewPacket()
+	p.msgID = 1
+	p.msgURI = "uri"
+	p.payload = []byte("payload")
+	for name, codec := range benchmarkCodecs {
+		data, err := codec.Marshal(p)
+		assert.Nil(t, err, name)
+		data = append([]byte(nil), data...)
+
+		// 截断的包,长度字段超出范围. CompactCodec payload为剩余数据,截断到URI
+		short := append([]byte(nil), data[:len(data)-2]...)
+		if codec == CompactCodec {
+			short = append([]byte(nil), data[:len(data)-len(p.payload)-2]...)
+		}
+		binary.BigEndian.PutUint32(short, uint32(len(short)-4))
+		assert.Equal(t, errcode.ErrInvalidPacket, codec.Unmarshal(short, NewPacket()), name)
+		// payload长度字段最大值,32位平台转换为int溢出
+		if codec != CompactCodec {
+			huge := append([]byte(nil), data...)
+			binary.BigEndian.PutUint32(huge[16:], 0xffffffff)
+			assert.Equal(t, errcode.ErrInvalidPacket, codec.Unmarshal(huge, NewPacket()), name)
+		}
+
+		MaxPayloadSize = 4
+		assert.Equal(t, errcode.ErrPacketsizeInvalid, codec.Unmarshal(data, NewPacket()), name)
+		MaxPayloadSize = maxPayloadSize
+	}
+
+	MaxURILength = 2
+	assert.Equal(t, errcode.ErrPacketsizeInvalid, CompactCodec.Unmarshal(mustMarshal(t, CompactCodec, p), NewPacket()))
+	// BytesURICodec 不使用 MaxURILength
+	assert.Nil(t, BytesURICodec.Unmarshal(mustMarshal(t, BytesURICodec, p), NewPacket()))
+	MaxURILength = maxURILength
+
+	p.msgURI = string(make([]byte, 256))
+	_, err := BytesURICodec.Marshal(p)
+	assert.Equal(t, errcode.ErrPacketsizeInvalid, err, "uri too long")
+}
+
+func mustMarshal(t *testing.T, codec Codec, p *Packet) []byte {
+	data, err := codec.Marshal(p)
+	assert.Nil(t, err)
+	return append([]byte(nil), data...)
+}
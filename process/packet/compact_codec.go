@@ -0,0 +1,195 @@
+package packet
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/metadata"
+)
+
+// compact codec 可选字段标记
+const (
+	compactReserved byte = 1 << iota
+	compactSessionID
+	compactMsgID
+	compactURI
+	compactMetadata
+
+	compactAll = compactReserved | compactSessionID | compactMsgID | compactURI | compactMetadata
+)
+
+// 4byte size 1byte cmd 1byte flag 1byte fields [1byte reserved] [varint sessionid] [varint id] [varint uri-len xbyte-uri] [varint md-len xbyte-metadata] xbyte-payload
+// fields 标记后续可选字段是否存在,为零值的字段不写入. 长度前缀和cmd,flag位置与 BytesURICodec 相同,gotcp分包和压缩编码器可以直接使用.
+type codecCompact struct{}
+
+// CompactCodec 同时支持消息ID和URI(MixRouter 优先匹配ID),可选字段使用varint编码.
+var CompactCodec Codec = codecCompact{}
+
+func (codecCompact) Marshal(p interface{}) ([]byte, error) {
+	pkg, ok := p.(*Packet)
+	if !ok {
+		return nil, errcode.ErrUnexpectedCode
+	}
+	md, err := metadata.GetCodec().Marshal(pkg.metadata)
+	if err != nil {
+		return nil, err
+	}
+	var fields byte
+	size := 3 + len(pkg.payload)
+	if pkg.reservd != 0 {
+		fields |= compactReserved
+		size++
+	}
+	if pkg.sessionID != 0 {
+		fields |= compactSessionID
+		size += uvarintSize(pkg.sessionID)
+	}
+	if pkg.msgID != 0 {
+		fields |= compactMsgID
+		size += uvarintSize(uint64(pkg.msgID))
+	}
+	if len(pkg.msgURI) > 0 {
+		fields |= compactURI
+		size += uvarintSize(uint64(len(pkg.msgURI))) + len(pkg.msgURI)
+	}
+	if len(md) > 0 {
+		fields |= compactMetadata
+		size += uvarintSize(uint64(len(md))) + len(md)
+	}
+	if size+4 > cap(pkg.cache) {
+		pkg.cache = make([]byte, size+4)
+	}
+	buf := pkg.cache[:size+4]
+	// payload可能引用cache(Unmarshal之后转发),先复制再写入包头
+	copy(buf[size+4-len(pkg.payload):], pkg.payload)
+	binary.BigEndian.PutUint32(buf, uint32(size))
+	buf[4] = byte(pkg.cmd)
+	buf[5] = byte(pkg.flag)
+	buf[6] = fields
+	idx := 7
+	if fields&compactReserved != 0 {
+		buf[idx] = pkg.reservd
+		idx++
+	}
+	if fields&compactSessionID != 0 {
+		idx += binary.PutUvarint(buf[idx:], pkg.sessionID)
+	}
+	if fields&compactMsgID != 0 {
+		idx += binary.PutUvarint(buf[idx:], uint64(pkg.msgID))
+	}
+	if fields&compactURI != 0 {
+		idx += binary.PutUvarint(buf[idx:], uint64(len(pkg.msgURI)))
+		idx += copy(buf[idx:], pkg.msgURI)
+	}
+	if fields&compactMetadata != 0 {
+		idx += binary.PutUvarint(buf[idx:], uint64(len(md)))
+		copy(buf[idx:], md)
+	}
+	return buf, nil
+}
+
+func (codecCompact) Unmarshal(data []byte, p interface{}) error {
+	if len(data) < 7 {
+		return errcode.ErrInvalidPacket
+	}
+	pkg, ok := p.(*Packet)
+	if !ok {
+		return errcode.ErrUnexpectedCode
+	}
+	if size := binary.BigEndian.Uint32(data); uint64(size)+4 != uint64(len(data)) {
+		return errcode.ErrPacketsizeInvalid
+	}
+	fields := data[6]
+	if fields&^compactAll != 0 {
+		return errcode.ErrInvalidPacket
+	}
+	pkg.cmd = PacketCmd(data[4])
+	pkg.flag = PacketFlag(data[5])
+	pkg.reservd = 0
+	pkg.sessionID = 0
+	pkg.msgID = 0
+	pkg.msgURI = ""
+	data = data[7:]
+	if fields&compactReserved != 0 {
+		if len(data) < 1 {
+			return errcode.ErrInvalidPacket
+		}
+		pkg.reservd = data[0]
+		data = data[1:]
+	}
+	var v uint64
+	var err error
+	if fields&compactSessionID != 0 {
+		if pkg.sessionID, data, err = readUvarint(data); err != nil {
+			return err
+		}
+	}
+	if fields&compactMsgID != 0 {
+		if v, data, err = readUvarint(data); err != nil {
+			return err
+		}
+		if v > math.MaxUint32 {
+			return errcode.ErrInvalidPacket
+		}
+		pkg.msgID = uint32(v)
+	}
+	if fields&compactURI != 0 {
+		var uri []byte
+		if uri, data, err = readBytes(data); err != nil {
+			return err
+		}
+		if len(uri) > MaxURILength {
+			return errcode.ErrPacketsizeInvalid
+		}
+		pkg.msgURI = string(uri)
+	}
+	var md []byte
+	if fields&compactMetadata != 0 {
+		if md, data, err = readBytes(data); err != nil {
+			return err
+		}
+	}
+	if len(data) > MaxPayloadSize {
+		return errcode.ErrPacketsizeInvalid
+	}
+	if len(data) > cap(pkg.cache) {
+		pkg.cache = make([]byte, len(data))
+	}
+	pkg.payload = pkg.cache[:len(data)]
+	copy(pkg.payload, data)
+	if len(md) == 0 {
+		return nil
+	}
+	if pkg.metadata == nil {
+		pkg.metadata = make(metadata.MD)
+	}
+	return metadata.GetCodec().Unmarshal(md, pkg.metadata)
+}
+
+func uvarintSize(x uint64) (n int) {
+	for n = 1; x >= 0x80; n++ {
+		x >>= 7
+	}
+	return
+}
+
+func readUvarint(data []byte) (v uint64, left []byte, err error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, data, errcode.ErrInvalidPacket
+	}
+	return v, data[n:], nil
+}
+
+// readBytes 读取varint长度前缀的数据
+func readBytes(data []byte) (v []byte, left []byte, err error) {
+	size, data, err := readUvarint(data)
+	if err != nil {
+		return nil, data, err
+	}
+	if size > uint64(len(data)) {
+		return nil, data, errcode.ErrInvalidPacket
+	}
+	return data[:size], data[size:], nil
+}
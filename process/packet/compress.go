@@ -0,0 +1,181 @@
+package packet
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrDecompressTooLarge 解压之后超过 MaxDecompressSize
+var ErrDecompressTooLarge = errors.New("decompressed packet too large")
+
+// MaxDecompressSize 解压之后的最大长度,防止恶意数据(压缩炸弹)占用大量内存.
+// 默认4M,远小于 MaxPayloadSize, 需要压缩传输更大的包时调整.
+var MaxDecompressSize = 4 << 20
+
+// Compressor 压缩算法
+type Compressor interface {
+	// Compress 压缩src,追加到dst之后返回
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress 解压src,追加到dst之后返回. 解压之后超过 maxSize 返回 ErrDecompressTooLarge
+	Decompress(dst, src []byte, maxSize int) ([]byte, error)
+}
+
+// compressHead 不压缩的包头: 4byte size 1byte cmd 1byte flag (BytesURICodec,BytesMIDCodec)
+const compressHead = 6
+
+// NewCompressEncoder 压缩网络包. 包长度大于等于threshold时压缩包头之后的数据,并在包头flag中设置 FlagCompressed,
+// 小包和压缩之后没有变小的包不做修改. 解码时只解压设置了 FlagCompressed 的包,解压失败返回nil.
+// 包格式需要与 BytesURICodec 一致(4字节长度,cmd,flag),通信双方使用相同的压缩算法.
+func NewCompressEncoder(c Compressor, threshold int) Encoder {
+	if threshold < compressHead+1 {
+		threshold = compressHead + 1
+	}
+	return &compressEncoder{
+		c:         c,
+		threshold: threshold,
+	}
+}
+
+// NewFlateEncoder 使用flate压缩. level 参考 compress/flate
+func NewFlateEncoder(level, threshold int) Encoder {
+	return NewCompressEncoder(NewFlateCompressor(level), threshold)
+}
+
+// NewGzipEncoder 使用gzip压缩. level 参考 compress/gzip
+func NewGzipEncoder(level, threshold int) Encoder {
+	return NewCompressEncoder(NewGzipCompressor(level), threshold)
+}
+
+type compressEncoder struct {
+	c         Compressor
+	threshold int
+}
+
+var _ Encoder = (*compressEncoder)(nil)
+
+func (enc *compressEncoder) Encode(buf []byte) []byte {
+	if len(buf) < enc.threshold || PacketFlag(buf[5])&FlagCompressed != 0 {
+		return buf
+	}
+	out := make([]byte, compressHead, len(buf))
+	copy(out, buf[:compressHead])
+	out, err := enc.c.Compress(out, buf[compressHead:])
+	if err != nil || len(out) >= len(buf) {
+		return buf
+	}
+	binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+	out[5] |= byte(FlagCompressed)
+	return out
+}
+
+func (enc *compressEncoder) Decode(buf []byte) []byte {
+	if len(buf) < compressHead || PacketFlag(buf[5])&FlagCompressed == 0 {
+		return buf
+	}
+	out := make([]byte, compressHead, len(buf)*4)
+	copy(out, buf[:compressHead])
+	out, err := enc.c.Decompress(out, buf[compressHead:], MaxDecompressSize)
+	if err != nil {
+		return nil
+	}
+	binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+	out[5] &^= byte(FlagCompressed)
+	return out
+}
+
+// NewFlateCompressor flate压缩算法
+func NewFlateCompressor(level int) Compressor {
+	c := &flateCompressor{}
+	c.writers.New = func() interface{} {
+		w, err := flate.NewWriter(nil, level)
+		if err != nil {
+			// 无效的level使用默认值
+			w, _ = flate.NewWriter(nil, flate.DefaultCompression)
+		}
+		return w
+	}
+	return c
+}
+
+type flateCompressor struct {
+	writers sync.Pool
+}
+
+func (c *flateCompressor) Compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w := c.writers.Get().(*flate.Writer)
+	defer c.writers.Put(w)
+	w.Reset(buf)
+	if _, err := w.Write(src); err != nil {
+		return dst, err
+	}
+	if err := w.Close(); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *flateCompressor) Decompress(dst, src []byte, maxSize int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(src))
+	defer r.Close()
+	return readLimit(dst, r, maxSize)
+}
+
+// NewGzipCompressor gzip压缩算法
+func NewGzipCompressor(level int) Compressor {
+	c := &gzipCompressor{}
+	c.writers.New = func() interface{} {
+		w, err := gzip.NewWriterLevel(nil, level)
+		if err != nil {
+			// 无效的level使用默认值
+			w = gzip.NewWriter(nil)
+		}
+		return w
+	}
+	return c
+}
+
+type gzipCompressor struct {
+	writers sync.Pool
+}
+
+func (c *gzipCompressor) Compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w := c.writers.Get().(*gzip.Writer)
+	defer c.writers.Put(w)
+	w.Reset(buf)
+	if _, err := w.Write(src); err != nil {
+		return dst, err
+	}
+	if err := w.Close(); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *gzipCompressor) Decompress(dst, src []byte, maxSize int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return dst, err
+	}
+	defer r.Close()
+	return readLimit(dst, r, maxSize)
+}
+
+// readLimit 读取全部数据追加到dst,超过maxSize返回 ErrDecompressTooLarge
+func readLimit(dst []byte, r io.Reader, maxSize int) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	n, err := buf.ReadFrom(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return dst, err
+	}
+	if n > int64(maxSize) {
+		return dst, ErrDecompressTooLarge
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+package packet
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/metadata"
+)
+
+func TestCompressEncoder(t *testing.T) {
+	for name, enc := range map[string]Encoder{
+		"flate": NewFlateEncoder(flate.BestSpeed, 64),
+		"gzip":  NewGzipEncoder(-100, 64),
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := NewPacket()
+			p.cmd = CmdResponse
+			p.flag = FlagError
+			p.msgURI = "rank"
+			p.payload = bytes.Repeat([]byte("rank-item;"), 100)
+			p.metadata = metadata.Pairs("k", "v")
+			raw, err := BytesURICodec.Marshal(p)
+			assert.Nil(t, err)
+			raw = append([]byte(nil), raw...)
+
+			data := enc.Encode(raw)
+			assert.Less(t, len(data), len(raw), "compressed")
+			assert.Equal(t, uint32(len(data)-4), binary.BigEndian.Uint32(data), "size")
+			assert.Equal(t, byte(CmdResponse), data[4], "cmd")
+			assert.Equal(t, FlagError|FlagCompressed, PacketFlag(data[5]), "flag")
+			assert.Equal(t, data, enc.Encode(data), "already compressed")
+
+			assert.Equal(t, raw, enc.Decode(data), "decode")
+			real := NewPacket()
+			assert.Nil(t, BytesURICodec.Unmarshal(enc.Decode(data), real))
+			assert.Equal(t, p.payload, real.payload)
+			assert.Equal(t, FlagError, real.flag)
+
+			// 小包不处理
+			p.payload = []byte("x")
+			small, err := BytesURICodec.Marshal(p)
+			assert.Nil(t, err)
+			assert.Equal(t, small, enc.Encode(small))
+			assert.Equal(t, small, enc.Decode(small))
+
+			// 数据错误
+			bad := append([]byte(nil), data...)
+			for k := 6; k < len(bad); k++ {
+				bad[k] = 0xff
+			}
+			assert.Nil(t, enc.Decode(bad), "corrupted")
+		})
+	}
+}
+
+func TestCompressEncoderLimit(t *testing.T) {
+	old := MaxDecompressSize
+	defer func() { MaxDecompressSize = old }()
+	enc := NewFlateEncoder(flate.DefaultCompression, 0)
+	p := NewPacket()
+	p.payload = make([]byte, 4096)
+	raw, err := BytesMIDCodec.Marshal(p)
+	assert.Nil(t, err)
+	data := enc.Encode(raw)
+	assert.Less(t, len(data), 100)
+	MaxDecompressSize = 1024
+	assert.Nil(t, enc.Decode(data))
+	MaxDecompressSize = len(raw)
+	assert.Equal(t, raw, enc.Decode(data))
+}
+
+type xorCoder byte
+
+func (x xorCoder) Encode(buf []byte) []byte {
+	out := make([]byte, len(buf))
+	for k, v := range buf {
+		out[k] = v ^ byte(x)
+	}
+	return out
+}
+
+func (x xorCoder) Decode(buf []byte) []byte {
+	return x.Encode(buf)
+}
+
+func TestTeeCoder(t *testing.T) {
+	// 先压缩再变换,解码顺序相反
+	tee := NewTeeCoder(NewFlateEncoder(flate.BestSpeed, 16), xorCoder(0x5a))
+	p := NewPacket()
+	p.payload = bytes.Repeat([]byte("abc"), 100)
+	raw, err := BytesURICodec.Marshal(p)
+	assert.Nil(t, err)
+	raw = append([]byte(nil), raw...)
+	data := tee.Encode(raw)
+	assert.Less(t, len(data), len(raw))
+	assert.Equal(t, raw, tee.Decode(data))
+}
@@ -53,9 +53,10 @@ func (tee *teePacketCoder) Encode(buf []byte) []byte {
 	return buf
 }
 
+// Decode 与 Encode 顺序相反
 func (tee *teePacketCoder) Decode(buf []byte) []byte {
-	for _, v := range tee.coders {
-		buf = v.Decode(buf)
+	for k := len(tee.coders) - 1; k >= 0; k-- {
+		buf = tee.coders[k].Decode(buf)
 	}
 	return buf
 }
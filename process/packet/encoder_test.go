@@ -0,0 +1,29 @@
+package packet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// suffixCoder 编码追加一个字节,解码检查并删除最后一个字节
+type suffixCoder byte
+
+func (c suffixCoder) Encode(buf []byte) []byte {
+	return append(buf, byte(c))
+}
+
+func (c suffixCoder) Decode(buf []byte) []byte {
+	if len(buf) < 1 || buf[len(buf)-1] != byte(c) {
+		return nil
+	}
+	return buf[:len(buf)-1]
+}
+
+func TestTeeCoderOrder(t *testing.T) {
+	tee := NewTeeCoder(suffixCoder('a'), suffixCoder('b'), suffixCoder('c'))
+	data := tee.Encode([]byte("x"))
+	assert.Equal(t, []byte("xabc"), data)
+	// Decode 逆序执行
+	assert.Equal(t, []byte("x"), tee.Decode(data))
+}
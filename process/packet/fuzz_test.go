@@ -0,0 +1,80 @@
+package packet
+
+import (
+	"compress/flate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fuzzSeedPackets() []*Packet {
+	p1 := NewPacket()
+	p1.cmd = CmdRequest
+	p1.sessionID = 1024
+	p1.msgID = 1001
+	p1.msgURI = "/room/chat"
+	p1.payload = []byte("payload")
+	p1.metadata["x-timeout"] = []string{"100"}
+	p2 := NewPacket()
+	p2.cmd = CmdResponse
+	p2.flag = FlagError
+	p2.reservd = 0x12
+	return []*Packet{p1, p2}
+}
+
+// fuzzCodec 任意输入不能panic,解码成功的包重新编码之后结果一致
+func fuzzCodec(f *testing.F, codec Codec) {
+	for _, p := range fuzzSeedPackets() {
+		data, err := codec.Marshal(p)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(append([]byte(nil), data...))
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := NewPacket()
+		if err := codec.Unmarshal(data, p); err != nil {
+			return
+		}
+		out, err := codec.Marshal(p)
+		if err != nil {
+			return
+		}
+		np := NewPacket()
+		err = codec.Unmarshal(append([]byte(nil), out...), np)
+		assert.Nil(t, err, "unmarshal marshaled packet")
+		p.CleanForTest()
+		np.CleanForTest()
+		assert.EqualValues(t, p, np, "marshal again")
+	})
+}
+
+func FuzzBytesURICodec(f *testing.F) {
+	fuzzCodec(f, BytesURICodec)
+}
+
+func FuzzBytesMIDCodec(f *testing.F) {
+	fuzzCodec(f, BytesMIDCodec)
+}
+
+func FuzzCompactCodec(f *testing.F) {
+	fuzzCodec(f, CompactCodec)
+}
+
+func FuzzFlateEncoder(f *testing.F) {
+	enc := NewFlateEncoder(flate.BestSpeed, 0)
+	for _, p := range fuzzSeedPackets() {
+		p.payload = make([]byte, 128)
+		data, err := BytesURICodec.Marshal(p)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(enc.Encode(data))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 解压失败返回nil,不能panic
+		enc.Decode(data)
+	})
+}
@@ -15,6 +15,10 @@ const (
 	CmdNotify PacketCmd = iota
 	CmdRequest
 	CmdResponse
+	// CmdStream stream frame sent by the stream opener
+	CmdStream
+	// CmdStreamReply stream frame sent by the stream acceptor
+	CmdStreamReply
 )
 
 // PacketFlag second byte,internal message flag.
@@ -23,6 +27,16 @@ type PacketFlag byte
 const (
 	// FlagError message is an error response
 	FlagError PacketFlag = 0x01
+	// FlagCompressed packet body is compressed by compress encoder
+	FlagCompressed PacketFlag = 0x02
+	// FlagStreamOpen open a new stream, sessionID is the stream id
+	FlagStreamOpen PacketFlag = 0x04
+	// FlagStreamEnd sender half-close, no more message
+	FlagStreamEnd PacketFlag = 0x08
+	// FlagStreamReset terminate stream, payload is error if FlagError set
+	FlagStreamReset PacketFlag = 0x10
+	// FlagStreamWindow flow control window update, payload is 4 byte credit
+	FlagStreamWindow PacketFlag = 0x20
 )
 
 // Encoder use for encode and decode source packet
@@ -102,6 +102,10 @@ func (p *Packet) Payload() []byte {
 	return p.payload
 }
 
+func (p *Packet) SetPayload(data []byte) {
+	p.payload = data
+}
+
 func (p *Packet) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
 	enc.AddInt8("cmd", int8(p.cmd))
 	enc.AddUint8("flag", uint8(p.flag))
@@ -86,6 +86,11 @@ func (w *packetProtocolWraper) NewResponse(inPkg, outPkg interface{}, md metadat
 	}
 	rsp.SetCmd(CmdResponse)
 	rsp.flag = 0
+	// 流式请求的处理函数直接响应(例如返回错误)时,结束流并把响应发送给打开方
+	if req.cmd == CmdStream {
+		rsp.SetCmd(CmdStreamReply)
+		rsp.flag = FlagStreamReset
+	}
 	rsp.reservd = req.reservd
 	rsp.sessionID = req.sessionID
 	rsp.msgURI = req.msgURI
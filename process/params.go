@@ -0,0 +1,26 @@
+package process
+
+// Param 路由参数
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params 路由参数列表. 路径 /room/:roomId/chat 匹配 /room/10/chat 时, roomId=10.
+type Params []Param
+
+// Get 获取参数值
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// ByName 获取参数值,不存在返回空字符串
+func (ps Params) ByName(name string) (v string) {
+	v, _ = ps.Get(name)
+	return
+}
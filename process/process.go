@@ -1,7 +1,10 @@
 package process
 
 import (
+	"sync"
+
 	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
 	"go.uber.org/zap"
 )
 
@@ -18,6 +21,7 @@ type Process struct {
 	Filter         ProcessFilter
 	dispatchData   DataDispatcherFunc
 	dispatchPacket PacketDispatcherFunc
+	executePacket  PacketDispatcherFunc
 }
 
 func NewProcess(inner *InnerOptions, opts *ProcessOptions) Process {
@@ -28,6 +32,7 @@ func NewProcess(inner *InnerOptions, opts *ProcessOptions) Process {
 	// 防止每次调用转换类型，申请堆
 	p.dispatchPacket = p.innerPacket
 	p.dispatchData = p.innerData
+	p.executePacket = p.innerExecute
 	return p
 }
 
@@ -51,6 +56,9 @@ func (p *Process) innerData(data []byte) (err error) {
 	err = p.Opts.PacketCodec.Unmarshal(data, pkg)
 	if err != nil {
 		p.Opts.FrameLogger.New("process.innerData").Error("unmarshal packet.Paket failed", zap.Error(err))
+		if p.Opts.Metrics {
+			metricUnmarshalErrors.Inc()
+		}
 		return err
 	}
 
@@ -59,9 +67,62 @@ func (p *Process) innerData(data []byte) (err error) {
 		return
 	}
 	// 请求包
+	if p.Opts.Executor != nil {
+		return p.Opts.DispatchPacketFilter(pkg, p.executePacket)
+	}
 	return p.Opts.DispatchPacketFilter(pkg, p.dispatchPacket)
 }
 
+// PacketDispatcher 可选接口. 路由处理长时间执行的消息包(流式请求),不阻塞读取协程.
+type PacketDispatcher interface {
+	DispatchPacket(pkg interface{}, done func(err error))
+}
+
+var _ PacketDispatcher = (*Process)(nil)
+
+// DispatchPacket 路由处理消息包,处理流程返回或者消息被拒绝时调用done.
+// 配置了Executor时在执行器内处理(保持消息顺序和队列限制),否则在新的协程处理(由调用方限制数量).
+func (p *Process) DispatchPacket(pkg interface{}, done func(err error)) {
+	var once sync.Once
+	finish := func(err error) {
+		once.Do(func() { done(err) })
+	}
+	next := func(pkg interface{}) (err error) {
+		err = p.dispatchPacket(pkg)
+		finish(err)
+		return
+	}
+	if p.Opts.Executor == nil {
+		go func() {
+			if err := p.Opts.DispatchPacketFilter(pkg, next); err != nil {
+				finish(err)
+			}
+		}()
+		return
+	}
+	err := p.Opts.DispatchPacketFilter(pkg, func(pkg interface{}) (err error) {
+		err = p.Opts.Executor.Dispatch(p.Inner, pkg, next)
+		if err != nil {
+			p.Opts.FrameLogger.New("process.DispatchPacket").Warn("executor dispatch failed", zap.Any("pkg", pkg), zap.Error(err))
+			p.Opts.PacketPool.Put(pkg)
+		}
+		return
+	})
+	if err != nil {
+		finish(err)
+	}
+}
+
+// innerExecute 切换到执行器协程处理消息
+func (p *Process) innerExecute(pkg interface{}) (err error) {
+	err = p.Opts.Executor.Dispatch(p.Inner, pkg, p.dispatchPacket)
+	if err != nil {
+		p.Opts.FrameLogger.New("process.innerExecute").Warn("executor dispatch failed", zap.Any("pkg", pkg), zap.Error(err))
+		p.Opts.PacketPool.Put(pkg)
+	}
+	return
+}
+
 func (p *Process) innerPacket(pkg interface{}) (err error) {
 	if p.Inner.Router == nil {
 		err = errcode.ErrUnexpectedCode
@@ -71,23 +132,88 @@ func (p *Process) innerPacket(pkg interface{}) (err error) {
 	}
 
 	// Request or Notice
-	handlers, err := p.Inner.Router.GetHandlers(pkg)
+	var handlers []RouterFunc
+	var params Params
+	stat := unknownRouteStat
+	switch r := p.Inner.Router.(type) {
+	case routeStatRouter:
+		handlers, params, stat, err = r.getHandlersStat(pkg)
+	case ParamsRouter:
+		handlers, params, err = r.GetHandlersParams(pkg)
+	default:
+		handlers, err = r.GetHandlers(pkg)
+	}
 	if err != nil {
 		p.Opts.FrameLogger.New("process.innerPacket").Warn("get handler failed", zap.Any("pkg", pkg), zap.Error(err))
+		if p.Opts.Metrics {
+			metricNoRouterErrors.Inc()
+		}
 		p.Opts.PacketPool.Put(pkg)
 		return err
 	}
 
-	// load limit
+	// load limit. 请求消息返回 ErrTooManyRequests
 	if p.Opts.LoadLimitFilter(pkg, p.Inner.Load) {
+		p.Opts.FrameLogger.New("process.innerPacket").Debug("process load limit", zap.Any("pkg", pkg))
+		if p.Opts.Metrics {
+			metricLoadLimitErrors.Inc()
+		}
+		p.replyError(pkg, errcode.ErrTooManyRequests)
 		p.Opts.PacketPool.Put(pkg)
 		p.Inner.Load.Dec()
-		p.Opts.FrameLogger.New("process.innerPacket").Debug("process load limit", zap.Any("pkg", pkg))
 		return
 	}
 
 	ctx := p.Inner.ContextPool.NewContext(p.Inner, p.Opts, pkg, handlers, true)
+	if len(params) > 0 {
+		ctx.SetParams(params)
+	}
+	// 调用方设置了等待时间,处理流程使用相同的截止时间(处理函数内的rpc调用继承剩余时间).
+	// 处理流程结束(回收Context)时取消,异步处理的中间件可以继续使用.
+	if ts, ok := ctx.(timeoutSetter); ok {
+		if md, err := p.Opts.PacketWraper.GetMetadata(pkg); err == nil {
+			if timeout, ok := md.Timeout(); ok {
+				ts.setTimeout(timeout)
+			}
+		}
+	}
+	var rs *requestStat
+	if p.Opts.Metrics {
+		rs = stat.begin(pkg)
+		if rs != nil {
+			AddRespondHook(ctx, rs.hook)
+		}
+	}
 	ctx.Next(ctx)
+	rs.done()
 
 	return
 }
+
+// replyError 请求消息直接返回错误
+func (p *Process) replyError(pkg interface{}, rspErr error) {
+	req, ok := pkg.(*packet.Packet)
+	if !ok || (req.Cmd() != packet.CmdRequest && req.Cmd() != packet.CmdStream) || p.Inner.Output == nil {
+		return
+	}
+	wp := p.Opts.PacketWraper
+	rsp := p.Opts.PacketPool.Get()
+	defer p.Opts.PacketPool.Put(rsp)
+	err := wp.NewResponse(pkg, rsp, nil)
+	if err == nil {
+		err = wp.PayloadMarshal(rsp, p.Opts.MsgCodec, rspErr)
+	}
+	var data []byte
+	if err == nil {
+		data, err = p.Opts.PacketCodec.Marshal(rsp)
+	}
+	if err == nil {
+		data = p.Opts.PacketEncode.Encode(data)
+	}
+	if err == nil {
+		_, err = p.Inner.Output.Write(data)
+	}
+	if err != nil {
+		p.Opts.FrameLogger.New("process.replyError").Error("reply error failed", zap.Error(err), zap.NamedError("reply", rspErr))
+	}
+}
@@ -3,6 +3,7 @@ package process
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -10,6 +11,7 @@ import (
 	"github.com/walleframe/walle/process/metadata"
 	"github.com/walleframe/walle/process/packet"
 	"github.com/walleframe/walle/testpkg"
+	"github.com/walleframe/walle/testpkg/mock_packet"
 	zaplog "github.com/walleframe/walle/zaplog"
 	zap "go.uber.org/zap"
 )
@@ -110,6 +112,88 @@ func TestProcess_OnRead(t *testing.T) {
 	p.OnRead(data)
 }
 
+// 请求metadata中的等待时间设置为处理流程的截止时间
+func TestProcess_Timeout(t *testing.T) {
+	called := false
+	r := NewTrieRouter()
+	r.Register("kk", func(ctx Context) {
+		called = true
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok, "deadline")
+		remain := time.Until(deadline)
+		assert.True(t, remain > 0 && remain <= 500*time.Millisecond, "remain time")
+	})
+
+	p := NewProcess(
+		NewInnerOptions(
+			WithInnerOptionOutput(&bytes.Buffer{}),
+			WithInnerOptionRouter(r),
+		),
+		NewProcessOptions(
+			WithLogger(zaplog.NewLogger(zap.NewNop())),
+			WithMsgCodec(message.JSONCodec),
+		),
+	)
+	rq := packet.NewTestPacket(packet.CmdNotify, []byte("{}"), metadata.WithTimeout(nil, 500*time.Millisecond))
+	rq.SetURI("kk")
+	data, err := packet.GetCodec().Marshal(rq)
+	assert.Nil(t, err, "marshal packet")
+	assert.Nil(t, p.OnRead(data), "on read")
+	assert.True(t, called, "handler called")
+
+	// 异步处理,处理流程结束之前截止时间不会被取消
+	done := make(chan error, 2)
+	r.Register("async", func(ctx Context) {
+		done <- ctx.Err()
+	}, func(ctx Context) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			done <- ctx.Err()
+			ctx.Next(ctx)
+		}()
+	})
+	rq = packet.NewTestPacket(packet.CmdNotify, []byte("{}"), metadata.WithTimeout(nil, 500*time.Millisecond))
+	rq.SetURI("async")
+	data, err = packet.GetCodec().Marshal(rq)
+	assert.Nil(t, err, "marshal packet")
+	assert.Nil(t, p.OnRead(data), "on read")
+	assert.Nil(t, <-done, "context canceled in middleware")
+	assert.Nil(t, <-done, "context canceled before handlers done")
+}
+
+// 读取时 Decode 解码,响应(包括直接返回的错误)使用 Encode 编码
+func TestProcess_Encode(t *testing.T) {
+	mc := gomock.NewController(t)
+	enc := mock_packet.NewMockEncoder(mc)
+	same := func(buf []byte) []byte { return buf }
+	enc.EXPECT().Decode(gomock.Any()).DoAndReturn(same).Times(2)
+	enc.EXPECT().Encode(gomock.Any()).DoAndReturn(same).Times(2)
+	r := NewTrieRouter()
+	r.Register("kk", func(ctx Context) {
+		ctx.Respond(ctx, &struct{}{}, nil)
+	})
+	out := &bytes.Buffer{}
+	p := NewProcess(
+		NewInnerOptions(
+			WithInnerOptionOutput(out),
+			WithInnerOptionRouter(r),
+		),
+		NewProcessOptions(
+			WithLogger(zaplog.NewLogger(zap.NewNop())),
+			WithMsgCodec(message.JSONCodec),
+			WithPacketEncode(enc),
+		),
+	)
+	rq := packet.NewTestPacket(packet.CmdRequest, []byte("{}"), nil)
+	rq.SetURI("kk")
+	data, err := packet.GetCodec().Marshal(rq)
+	assert.Nil(t, err, "marshal packet")
+	assert.Nil(t, p.OnRead(data), "respond")
+	assert.NotZero(t, out.Len())
+	p.Opts.LoadLimitFilter = func(req interface{}, count AtomicNumber) bool { return true }
+	assert.Nil(t, p.OnRead(data), "reply error")
+}
+
 func BenchmarkProcess(b *testing.B) {
 
 	type testJsonST struct {
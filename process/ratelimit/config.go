@@ -0,0 +1,33 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/walleframe/walle/services/configcentra"
+)
+
+// WatchConfig 注册配置 prefix.rate, prefix.burst,配置更新时修改限制. 需要在init函数内调用.
+func (l *TokenBucket) WatchConfig(prefix string) {
+	l.mux.Lock()
+	rate, burst := l.rate, int(l.burst)
+	l.mux.Unlock()
+	configcentra.Float64(&rate, prefix+".rate", rate, "rate limit tokens per second, <=0 disable", func(val float64) {
+		l.SetLimit(val, burst)
+	})
+	configcentra.Int(&burst, prefix+".burst", burst, "rate limit burst size", func(val int) {
+		l.SetLimit(rate, val)
+	})
+}
+
+// WatchConfig 注册配置 prefix.limit, prefix.window,配置更新时修改限制. 需要在init函数内调用.
+func (l *SlidingWindow) WatchConfig(prefix string) {
+	l.mux.Lock()
+	limit, window := l.limit, l.window
+	l.mux.Unlock()
+	configcentra.Int(&limit, prefix+".limit", limit, "rate limit requests per window, <=0 disable", func(val int) {
+		l.SetLimit(val, window)
+	})
+	configcentra.Duration(&window, prefix+".window", window, "rate limit window size", func(val time.Duration) {
+		l.SetLimit(limit, val)
+	})
+}
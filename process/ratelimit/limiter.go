@@ -0,0 +1,199 @@
+// Package ratelimit 限流器. 支持令牌桶和滑动窗口,按照会话,路由或者远端地址限流.
+// 可以作为路由中间件( Middleware )或者 ProcessOptions.LoadLimitFilter ( LoadLimitFilter )使用.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter 限流器. key 为限流对象(会话,路由,IP等)
+type Limiter interface {
+	Allow(key interface{}) bool
+}
+
+// sweepInterval 清理空闲key的间隔
+const sweepInterval = time.Minute
+
+// TokenBucket 令牌桶限流. 每个key每秒补充 rate 个令牌,最多 burst 个.
+type TokenBucket struct {
+	mux       sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[interface{}]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// NewTokenBucket new token bucket limiter. rate<=0 不限流, burst 最小为1
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:    rate,
+		burst:   burstSize(burst),
+		buckets: make(map[interface{}]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetLimit 修改限制,立即生效
+func (l *TokenBucket) SetLimit(rate float64, burst int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.rate = rate
+	l.burst = burstSize(burst)
+	for _, b := range l.buckets {
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+	}
+}
+
+// Allow 获取一个令牌
+func (l *TokenBucket) Allow(key interface{}) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.rate <= 0 {
+		return true
+	}
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func burstSize(burst int) float64 {
+	if burst < 1 {
+		return 1
+	}
+	return float64(burst)
+}
+
+func (l *TokenBucket) refill(b *bucket, now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+}
+
+// sweep 删除令牌已经补满的key
+func (l *TokenBucket) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// SlidingWindow 滑动窗口限流. 每个key在任意 window 时间内最多 limit 次.
+// 使用前一个窗口的计数按照时间加权估算,不记录每次请求的时间.
+type SlidingWindow struct {
+	mux       sync.Mutex
+	limit     int
+	window    time.Duration
+	counters  map[interface{}]*counter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type counter struct {
+	start time.Time
+	prev  int
+	cur   int
+}
+
+var _ Limiter = (*SlidingWindow)(nil)
+
+// NewSlidingWindow new sliding window limiter. limit<=0 或者 window<=0 不限流
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		limit:    limit,
+		window:   window,
+		counters: make(map[interface{}]*counter),
+		now:      time.Now,
+	}
+}
+
+// SetLimit 修改限制. 修改窗口大小时重新计数
+func (l *SlidingWindow) SetLimit(limit int, window time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if window != l.window {
+		l.counters = make(map[interface{}]*counter)
+	}
+	l.limit = limit
+	l.window = window
+}
+
+// Allow 记录一次请求
+func (l *SlidingWindow) Allow(key interface{}) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.limit <= 0 || l.window <= 0 {
+		return true
+	}
+	now := l.now()
+	l.sweep(now)
+	c, ok := l.counters[key]
+	if !ok {
+		c = &counter{start: now.Truncate(l.window)}
+		l.counters[key] = c
+	}
+	l.slide(c, now)
+	weight := 1 - float64(now.Sub(c.start))/float64(l.window)
+	if float64(c.prev)*weight+float64(c.cur) >= float64(l.limit) {
+		return false
+	}
+	c.cur++
+	return true
+}
+
+// slide 移动到当前窗口
+func (l *SlidingWindow) slide(c *counter, now time.Time) {
+	start := now.Truncate(l.window)
+	switch {
+	case start.Equal(c.start):
+	case start.Sub(c.start) == l.window:
+		c.prev, c.cur = c.cur, 0
+		c.start = start
+	default:
+		c.prev, c.cur = 0, 0
+		c.start = start
+	}
+}
+
+// sweep 删除超过两个窗口没有请求的key
+func (l *SlidingWindow) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.counters {
+		if now.Sub(c.start) >= 2*l.window {
+			delete(l.counters, key)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := NewTokenBucket(2, 3)
+	l.now = clock.Now
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("a"))
+	}
+	assert.False(t, l.Allow("a"))
+	// 不同key独立计数
+	assert.True(t, l.Allow("b"))
+	// 每秒补充2个
+	clock.now = clock.now.Add(time.Second)
+	assert.True(t, l.Allow("a"))
+	assert.True(t, l.Allow("a"))
+	assert.False(t, l.Allow("a"))
+	// 修改限制
+	l.SetLimit(0, 1)
+	assert.True(t, l.Allow("a"))
+	// 清理空闲key
+	l.SetLimit(1, 1)
+	clock.now = clock.now.Add(2 * sweepInterval)
+	assert.True(t, l.Allow("c"))
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := NewSlidingWindow(4, time.Second)
+	l.now = clock.Now
+	for i := 0; i < 4; i++ {
+		assert.True(t, l.Allow("a"))
+	}
+	assert.False(t, l.Allow("a"))
+	// 半个窗口之后,上个窗口计数权重0.5
+	clock.now = clock.now.Add(1500 * time.Millisecond)
+	assert.True(t, l.Allow("a"))
+	assert.True(t, l.Allow("a"))
+	assert.False(t, l.Allow("a"))
+	// 超过两个窗口重新计数
+	clock.now = clock.now.Add(2 * time.Second)
+	for i := 0; i < 4; i++ {
+		assert.True(t, l.Allow("a"))
+	}
+	l.SetLimit(0, time.Second)
+	assert.True(t, l.Allow("a"))
+}
+
+func newTestProcess(r process.Router, out *bytes.Buffer, opts ...process.ProcessOption) process.Process {
+	return process.NewProcess(
+		process.NewInnerOptions(
+			process.WithInnerOptionRouter(r),
+			process.WithInnerOptionOutput(out),
+		),
+		process.NewProcessOptions(opts...),
+	)
+}
+
+// send 发送请求,返回错误响应
+func send(t *testing.T, p process.Process, out *bytes.Buffer, cmd packet.PacketCmd, uri string) (sent bool, rspErr error) {
+	req := packet.NewPacket()
+	req.SetCmd(cmd)
+	req.SetURI(uri)
+	data, err := p.Opts.PacketCodec.Marshal(req)
+	assert.Nil(t, err)
+	out.Reset()
+	assert.Nil(t, p.OnRead(data))
+	if out.Len() == 0 {
+		return false, nil
+	}
+	rsp := packet.NewPacket()
+	assert.Nil(t, p.Opts.PacketCodec.Unmarshal(out.Bytes(), rsp))
+	return true, p.Opts.PacketWraper.PayloadUnmarshal(rsp, p.Opts.MsgCodec, nil)
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	r := &process.MixRouter{}
+	r.Use(Middleware(NewTokenBucket(0.001, 1), ByURI))
+	handler := func(ctx process.Context) {
+		calls++
+		ctx.Respond(ctx, nil, nil)
+	}
+	r.Register("/a", handler)
+	r.Register("/b", handler)
+	out := &bytes.Buffer{}
+	p := newTestProcess(r, out)
+
+	sent, err := send(t, p, out, packet.CmdRequest, "/a")
+	assert.True(t, sent)
+	assert.Nil(t, err)
+	sent, err = send(t, p, out, packet.CmdRequest, "/a")
+	assert.True(t, sent)
+	assert.True(t, errcode.Is(err, errcode.ErrorCodeTooManyRequests))
+	// 通知消息不响应
+	sent, _ = send(t, p, out, packet.CmdNotify, "/a")
+	assert.False(t, sent)
+	// 其他路由不受影响
+	sent, err = send(t, p, out, packet.CmdRequest, "/b")
+	assert.True(t, sent)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	// 默认 LoadLimitFilter 不计数,限流的请求同样回收
+	assert.Equal(t, int64(-4), p.Inner.Load.Load())
+}
+
+func TestLoadLimitFilter(t *testing.T) {
+	calls := 0
+	r := &process.MixRouter{}
+	r.Register("/a", func(ctx process.Context) {
+		calls++
+	})
+	out := &bytes.Buffer{}
+	p := newTestProcess(r, out, process.WithLoadLimitFilter(LoadLimitFilter(NewSlidingWindow(1, time.Hour), PacketURI)))
+
+	sent, _ := send(t, p, out, packet.CmdRequest, "/a")
+	assert.False(t, sent)
+	sent, err := send(t, p, out, packet.CmdRequest, "/a")
+	assert.True(t, sent)
+	assert.True(t, errcode.Is(err, errcode.ErrorCodeTooManyRequests))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"net"
+
+	"github.com/walleframe/walle/network"
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
+	"go.uber.org/zap"
+)
+
+// KeyFunc 限流key. 返回nil不限流
+type KeyFunc func(ctx process.Context) interface{}
+
+// PacketKeyFunc LoadLimitFilter 使用的限流key,只能获取请求包. 返回nil不限流
+type PacketKeyFunc func(pkg interface{}) interface{}
+
+// Global 所有请求共用限制
+func Global(ctx process.Context) interface{} {
+	return ""
+}
+
+// BySession 按照会话限流(网络层会话)
+func BySession(ctx process.Context) interface{} {
+	if sess, ok := ctx.(network.Session); ok {
+		return sess.GetConn()
+	}
+	return nil
+}
+
+// ByRemoteAddr 按照远端IP限流
+func ByRemoteAddr(ctx process.Context) interface{} {
+	sess, ok := ctx.(network.Session)
+	if !ok {
+		return nil
+	}
+	conn, ok := sess.GetConn().(interface{ RemoteAddr() net.Addr })
+	if !ok || conn.RemoteAddr() == nil {
+		return nil
+	}
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// ByURI 按照路由限流
+func ByURI(ctx process.Context) interface{} {
+	return PacketURI(ctx.GetRequestPacket())
+}
+
+// PacketURI 按照路由限流,消息ID优先
+func PacketURI(pkg interface{}) interface{} {
+	p, ok := pkg.(*packet.Packet)
+	if !ok {
+		return nil
+	}
+	if p.MsgID() > 0 {
+		return p.MsgID()
+	}
+	return p.URI()
+}
+
+// Middleware 限流中间件. 超过限制时请求消息返回 errcode.ErrTooManyRequests,通知消息直接丢弃.
+func Middleware(l Limiter, key KeyFunc) process.MiddlewareFunc {
+	return func(ctx process.Context) {
+		k := key(ctx)
+		if k == nil || l.Allow(k) {
+			ctx.Next(ctx)
+			return
+		}
+		if p, ok := ctx.GetRequestPacket().(*packet.Packet); ok && p.Cmd() == packet.CmdRequest {
+			if err := ctx.Respond(ctx, errcode.ErrTooManyRequests, nil); err != nil {
+				ctx.Logger().New("ratelimit.Middleware").Error("respond failed", zap.Error(err))
+			}
+		}
+		// 终止调用链,回收请求包和Context
+		ctx.Abort()
+		ctx.Next(ctx)
+	}
+}
+
+// LoadLimitFilter 作为 process.WithLoadLimitFilter 使用. 超过限制时 process 返回 errcode.ErrTooManyRequests.
+// LoadLimitFilter 只能获取请求包,按照会话或者IP限流需要使用 Middleware.
+func LoadLimitFilter(l Limiter, key PacketKeyFunc) func(req interface{}, count process.AtomicNumber) bool {
+	return func(req interface{}, count process.AtomicNumber) bool {
+		k := key(req)
+		return k != nil && !l.Allow(k)
+	}
+}
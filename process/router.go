@@ -27,8 +27,24 @@ type Router interface {
 	Register(uri interface{}, rf RouterFunc, m ...MiddlewareFunc) (err error)
 	// GetHandlers 获取处理函数接口
 	GetHandlers(p interface{}) (handlers []RouterFunc, err error)
+	// Group 新建路由分组. 分组内string类型路由添加前缀,分组中间件作用于分组内所有路由(与注册顺序无关)
+	Group(prefix string, m ...MiddlewareFunc) Router
 }
 
+// ParamsRouter 可选接口. 路由支持路径参数,参数通过 Context.Params 获取.
+type ParamsRouter interface {
+	Router
+	// GetHandlersParams 获取处理函数和路径参数
+	GetHandlersParams(p interface{}) (handlers []RouterFunc, params Params, err error)
+}
+
+var (
+	_ RouteLister = (*MixRouter)(nil)
+	_ RouteLister = (*TrieRouter)(nil)
+	_ RouteLister = (*COWRouter)(nil)
+	_ RouteLister = (*routerGroup)(nil)
+)
+
 var defaultRouter Router = &MixRouter{}
 
 func GetRouter() Router {
@@ -39,10 +55,6 @@ func SetRouter(r Router) {
 	defaultRouter = r
 }
 
-type routerNode struct {
-	funs []RouterFunc
-}
-
 // MixRouter 混合路由. 优先使用RequestID.(内部功能也使用RequestID)
 // 路由必须先注册,再使用. 已经开始使用之后,路由不能再修改.(内部无锁)
 type MixRouter struct {
@@ -74,63 +86,77 @@ func (r *MixRouter) NoRouter(rf RouterFunc, mid ...MiddlewareFunc) (err error) {
 }
 
 func (r *MixRouter) Register(uri interface{}, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+	return r.registerNode(uri, r.newNode(nil, rf, m...))
+}
+
+// Group 新建路由分组
+func (r *MixRouter) Group(prefix string, m ...MiddlewareFunc) Router {
+	return newRouterGroup(r, nil, prefix, m...)
+}
+
+func (r *MixRouter) newNode(group *routerGroup, rf RouterFunc, m ...MiddlewareFunc) *routerNode {
+	return newRouterNode(r.middlewares, group, rf, m...)
+}
+
+func (r *MixRouter) update(f func()) error {
+	f()
+	return nil
+}
+
+func (r *MixRouter) registerNode(uri interface{}, node *routerNode) (err error) {
 	switch v := uri.(type) {
 	case string:
-		return r.regMethod(v, rf, m...)
+		err = r.regMethod(v, node)
 	case uint32:
-		return r.regRequestID(v, rf, m...)
+		err = r.regRequestID(v, node)
 	case int:
-		return r.regRequestID(uint32(v), rf, m...)
+		err = r.regRequestID(uint32(v), node)
 	default:
 		// 未支持的类型
-		return errcode.WrapError(errcode.ErrUnexpectedCode,
+		err = errcode.WrapError(errcode.ErrUnexpectedCode,
 			fmt.Errorf("register uri type[%s] not suppert", reflect.TypeOf(uri).Name()),
 		)
 	}
+	if err == nil && node.stat == nil {
+		node.stat = newRouteStat(uri)
+	}
+	if err == nil && node.group != nil {
+		node.group.nodes = append(node.group.nodes, node)
+	}
+	return warnRegister(uri, node, err)
 }
 
 // Method 使用string类型名字注册处理方法
-func (r *MixRouter) regMethod(name string, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+func (r *MixRouter) regMethod(name string, node *routerNode) (err error) {
 	if r.handlers == nil {
 		r.handlers = make(map[string]*routerNode)
 	}
-	if _, ok := r.handlers[name]; ok {
-		return fmt.Errorf("method %s %w", name, ErrRouterKeyRepated)
+	if exist, ok := r.handlers[name]; ok {
+		return fmt.Errorf("method %s registered by %s, %w", name, exist.name(), ErrRouterKeyRepated)
 	}
-	node := new(routerNode)
-	node.funs = make([]MiddlewareFunc, len(r.middlewares)+len(m)+1)
-	copy(node.funs, r.middlewares)
-	if len(m) > 0 {
-		copy(node.funs[len(r.middlewares):], m)
-	}
-	node.funs[len(node.funs)-1] = rf
 	r.handlers[name] = node
-
 	return
 }
 
 // RequestID 使用请求id注册处理方法
-func (r *MixRouter) regRequestID(id uint32, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+func (r *MixRouter) regRequestID(id uint32, node *routerNode) (err error) {
 	if r.handlersID == nil {
 		r.handlersID = make(map[uint32]*routerNode)
 	}
-	if _, ok := r.handlersID[id]; ok {
-		return fmt.Errorf("RQID %d %w", id, ErrRouterKeyRepated)
+	if exist, ok := r.handlersID[id]; ok {
+		return fmt.Errorf("RQID %d registered by %s, %w", id, exist.name(), ErrRouterKeyRepated)
 	}
-	node := new(routerNode)
-	node.funs = make([]MiddlewareFunc, len(r.middlewares)+len(m)+1)
-	copy(node.funs, r.middlewares)
-	if len(m) > 0 {
-		copy(node.funs[len(r.middlewares):], m)
-	}
-	node.funs[len(node.funs)-1] = rf
 	r.handlersID[id] = node
-
 	return
 }
 
 // GetHandlers 获取请求对应处理函数
 func (r *MixRouter) GetHandlers(in interface{}) (handlers []RouterFunc, err error) {
+	handlers, _, _, err = r.getHandlersStat(in)
+	return
+}
+
+func (r *MixRouter) getHandlersStat(in interface{}) (handlers []RouterFunc, params Params, stat *routeStat, err error) {
 	if in == nil {
 		err = ErrNotFoundRequest
 		return
@@ -138,7 +164,7 @@ func (r *MixRouter) GetHandlers(in interface{}) (handlers []RouterFunc, err erro
 	p := in.(*packet.Packet)
 	if r.handlersID != nil && p.MsgID() > 0 {
 		if node, ok := r.handlersID[p.MsgID()]; ok {
-			return node.funs, nil
+			return node.funs, nil, node.stat, nil
 		}
 	}
 	node, ok := r.handlers[p.URI()]
@@ -147,16 +173,26 @@ func (r *MixRouter) GetHandlers(in interface{}) (handlers []RouterFunc, err erro
 			err = fmt.Errorf("uri %s %w", p.URI(), ErrRouterNotSupport)
 			return
 		}
-		handlers = r.noCache
-		return
+		return r.noCache, nil, noRouterStat, nil
 	}
-	handlers = node.funs
-	return
+	return node.funs, nil, node.stat, nil
+}
+
+// Routes 已注册路由
+func (r *MixRouter) Routes() (routes []RouteInfo) {
+	for id, node := range r.handlersID {
+		routes = append(routes, routeInfo(id, node.funs))
+	}
+	for name, node := range r.handlers {
+		routes = append(routes, routeInfo(name, node.funs))
+	}
+	return append(routes, noRouterInfo(r.noCache)...)
 }
 
 // 路由返回通用错误
 var (
-	ErrRouterKeyRepated = errors.New("router key repeated")
-	ErrRouterNotSupport = errors.New("router not found")
-	ErrNotFoundRequest  = errors.New("no request packet")
+	ErrRouterKeyRepated  = errors.New("router key repeated")
+	ErrRouterNotSupport  = errors.New("router not found")
+	ErrNotFoundRequest   = errors.New("no request packet")
+	ErrRouterInvalidPath = errors.New("router invalid path")
 )
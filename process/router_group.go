@@ -0,0 +1,201 @@
+package process
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+// nodeRegister 路由实现注册路由节点,用于支持路由分组
+type nodeRegister interface {
+	Router
+	// newNode 新建路由节点,记录当前全局中间件
+	newNode(group *routerGroup, rf RouterFunc, m ...MiddlewareFunc) *routerNode
+	// registerNode 注册路由节点
+	registerNode(uri interface{}, node *routerNode) error
+	// update 修改路由分组
+	update(f func()) error
+}
+
+// routerGroup 路由分组. 分组中间件作用于分组内所有路由,与注册顺序无关.
+type routerGroup struct {
+	root        nodeRegister
+	parent      *routerGroup
+	prefix      string
+	middlewares []MiddlewareFunc
+	nodes       []*routerNode
+	children    []*routerGroup
+}
+
+var _ ParamsRouter = (*routerGroup)(nil)
+
+func newRouterGroup(root nodeRegister, parent *routerGroup, prefix string, m ...MiddlewareFunc) *routerGroup {
+	g := &routerGroup{
+		root:        root,
+		parent:      parent,
+		prefix:      prefix,
+		middlewares: m,
+	}
+	if parent != nil {
+		g.prefix = joinPath(parent.prefix, prefix)
+		parent.children = append(parent.children, g)
+	}
+	return g
+}
+
+// Use 设置分组中间件,作用于分组(包括子分组)内所有路由
+func (g *routerGroup) Use(m ...MiddlewareFunc) {
+	if len(m) < 1 {
+		return
+	}
+	err := g.root.update(func() {
+		g.middlewares = append(g.middlewares, m...)
+		g.rebuild()
+	})
+	if err != nil {
+		zaplog.GetFrameLogger().New("process.routerGroup").Error("use middleware failed", zap.Error(err))
+	}
+}
+
+// NoRouter 设置未注册路由的处理函数,使用当前分组中间件
+func (g *routerGroup) NoRouter(rf RouterFunc, mid ...MiddlewareFunc) (err error) {
+	return g.root.NoRouter(rf, append(g.chain(), mid...)...)
+}
+
+// Register 注册路由. string类型路由添加分组前缀,消息ID不变.
+func (g *routerGroup) Register(uri interface{}, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+	switch v := uri.(type) {
+	case string:
+		uri = joinPath(g.prefix, v)
+	case uint32, int:
+	default:
+		// 未支持的类型
+		return errcode.WrapError(errcode.ErrUnexpectedCode,
+			fmt.Errorf("register uri type[%s] not suppert", reflect.TypeOf(uri).Name()),
+		)
+	}
+	return g.root.registerNode(uri, g.root.newNode(g, rf, m...))
+}
+
+// GetHandlers 获取处理函数接口
+func (g *routerGroup) GetHandlers(p interface{}) (handlers []RouterFunc, err error) {
+	return g.root.GetHandlers(p)
+}
+
+func (g *routerGroup) getHandlersStat(p interface{}) (handlers []RouterFunc, params Params, stat *routeStat, err error) {
+	if r, ok := g.root.(routeStatRouter); ok {
+		return r.getHandlersStat(p)
+	}
+	handlers, params, err = g.GetHandlersParams(p)
+	return handlers, params, unknownRouteStat, err
+}
+
+// GetHandlersParams 获取处理函数和路径参数
+func (g *routerGroup) GetHandlersParams(p interface{}) (handlers []RouterFunc, params Params, err error) {
+	if r, ok := g.root.(ParamsRouter); ok {
+		return r.GetHandlersParams(p)
+	}
+	handlers, err = g.root.GetHandlers(p)
+	return
+}
+
+// Routes 已注册路由(包括分组外的路由)
+func (g *routerGroup) Routes() (routes []RouteInfo) {
+	if r, ok := g.root.(RouteLister); ok {
+		return r.Routes()
+	}
+	return
+}
+
+// Group 新建子分组
+func (g *routerGroup) Group(prefix string, m ...MiddlewareFunc) Router {
+	return newRouterGroup(g.root, g, prefix, m...)
+}
+
+// chain 分组中间件(包括父分组)
+func (g *routerGroup) chain() (m []MiddlewareFunc) {
+	if g == nil {
+		return nil
+	}
+	m = append(m, g.parent.chain()...)
+	return append(m, g.middlewares...)
+}
+
+// removeNode 删除分组内路由
+func (g *routerGroup) removeNode(node *routerNode) {
+	for k, v := range g.nodes {
+		if v == node {
+			g.nodes = append(g.nodes[:k], g.nodes[k+1:]...)
+			return
+		}
+	}
+}
+
+// replaceNode 替换分组内路由
+func (g *routerGroup) replaceNode(old, node *routerNode) {
+	for k, v := range g.nodes {
+		if v == old {
+			g.nodes[k] = node
+			return
+		}
+	}
+}
+
+// rebuild 重新生成分组内路由调用链
+func (g *routerGroup) rebuild() {
+	for _, node := range g.nodes {
+		node.build()
+	}
+	for _, child := range g.children {
+		child.rebuild()
+	}
+}
+
+// routerNode 路由节点
+type routerNode struct {
+	funs []RouterFunc
+	// 注册时的全局中间件
+	global []MiddlewareFunc
+	group  *routerGroup
+	mids   []MiddlewareFunc
+	rf     RouterFunc
+	// 监控指标,注册时设置
+	stat *routeStat
+}
+
+// newRouterNode 新建路由节点. 全局中间件 -> 分组中间件 -> 路由中间件 -> 路由函数
+func newRouterNode(global []MiddlewareFunc, group *routerGroup, rf RouterFunc, m ...MiddlewareFunc) *routerNode {
+	node := &routerNode{
+		global: append([]MiddlewareFunc(nil), global...),
+		group:  group,
+		mids:   m,
+		rf:     rf,
+	}
+	node.build()
+	return node
+}
+
+func (node *routerNode) build() {
+	chain := node.group.chain()
+	funs := make([]RouterFunc, 0, len(node.global)+len(chain)+len(node.mids)+1)
+	funs = append(funs, node.global...)
+	funs = append(funs, chain...)
+	funs = append(funs, node.mids...)
+	funs = append(funs, node.rf)
+	node.funs = funs
+}
+
+// joinPath 拼接分组前缀和路由
+func joinPath(prefix, uri string) string {
+	if prefix == "" {
+		return uri
+	}
+	if uri == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(uri, "/")
+}
@@ -0,0 +1,64 @@
+package process
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/packet"
+)
+
+func TestRouterGroup(t *testing.T) {
+	routers := map[string]func() Router{
+		"mix":  func() Router { return &MixRouter{} },
+		"trie": func() Router { return NewTrieRouter() },
+	}
+	for name, newRouter := range routers {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			mid := func(name string) MiddlewareFunc {
+				return func(ctx Context) {
+					calls = append(calls, name)
+				}
+			}
+			r := newRouter()
+			admin := r.Group("/admin", mid("auth"))
+			gm := r.Group("/gm/")
+			// 注册之后设置的分组中间件同样生效
+			assert.Nil(t, admin.Register("/kick", mid("kick")))
+			assert.Nil(t, gm.Register("ban", mid("ban"), mid("ban-mid")))
+			assert.Nil(t, gm.Register(uint32(10), mid("id")))
+			sub := admin.Group("user", mid("user"))
+			assert.Nil(t, sub.Register("/info", mid("info")))
+			admin.Use(mid("admin-log"))
+			gm.Use(mid("audit"))
+			assert.Nil(t, r.Register("/login", mid("login")))
+
+			datas := []struct {
+				uri    string
+				msgID  uint32
+				expect []string
+			}{
+				{uri: "/admin/kick", expect: []string{"auth", "admin-log", "kick"}},
+				{uri: "/gm/ban", expect: []string{"audit", "ban-mid", "ban"}},
+				{msgID: 10, expect: []string{"audit", "id"}},
+				{uri: "/admin/user/info", expect: []string{"auth", "admin-log", "user", "info"}},
+				{uri: "/login", expect: []string{"login"}},
+			}
+			for _, v := range datas {
+				pkg := packet.NewPacket()
+				pkg.SetURI(v.uri)
+				pkg.SetMsgID(v.msgID)
+				handlers, err := r.GetHandlers(pkg)
+				assert.Nil(t, err, v.uri)
+				calls = calls[:0]
+				for _, h := range handlers {
+					h(nil)
+				}
+				assert.Equal(t, v.expect, calls, "uri:%s id:%d", v.uri, v.msgID)
+			}
+
+			assert.True(t, errors.Is(gm.Register("/ban", mid("ban")), ErrRouterKeyRepated))
+		})
+	}
+}
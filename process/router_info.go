@@ -0,0 +1,126 @@
+package process
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/walleframe/walle/util"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+// RouteInfo 路由信息
+type RouteInfo struct {
+	// URI string类型路由
+	URI string `json:"uri,omitempty"`
+	// MsgID 消息ID路由
+	MsgID uint32 `json:"msg_id,omitempty"`
+	// NoRouter 未注册路由的默认处理
+	NoRouter bool `json:"no_router,omitempty"`
+	// Handler 路由函数名称
+	Handler string `json:"handler"`
+	// Middlewares 中间件函数名称(调用顺序)
+	Middlewares []string `json:"middlewares,omitempty"`
+}
+
+// RouteLister 可选接口. 枚举已注册的路由
+type RouteLister interface {
+	Routes() []RouteInfo
+}
+
+// ListRoutes 获取路由表,消息ID路由在前,string类型路由按照名称排序
+func ListRoutes(r Router) (routes []RouteInfo) {
+	lister, ok := r.(RouteLister)
+	if !ok {
+		return
+	}
+	routes = lister.Routes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.NoRouter != b.NoRouter {
+			return b.NoRouter
+		}
+		if (a.MsgID > 0) != (b.MsgID > 0) {
+			return a.MsgID > 0
+		}
+		if a.MsgID != b.MsgID {
+			return a.MsgID < b.MsgID
+		}
+		return a.URI < b.URI
+	})
+	return
+}
+
+// DumpRoutes 输出路由表
+func DumpRoutes(w io.Writer, r Router) (err error) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MSGID\tURI\tHANDLER\tMIDDLEWARES")
+	for _, v := range ListRoutes(r) {
+		id, uri := "-", v.URI
+		if v.MsgID > 0 {
+			id = fmt.Sprint(v.MsgID)
+		}
+		if v.NoRouter {
+			uri = "<norouter>"
+		}
+		if uri == "" {
+			uri = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", id, uri, v.Handler, strings.Join(v.Middlewares, ","))
+	}
+	return tw.Flush()
+}
+
+// routeInfo 路由节点信息
+func routeInfo(uri interface{}, funs []MiddlewareFunc) (info RouteInfo) {
+	switch v := uri.(type) {
+	case string:
+		info.URI = v
+	case uint32:
+		info.MsgID = v
+	}
+	if len(funs) == 0 {
+		return
+	}
+	info.Handler = util.GetFunctionName(funs[len(funs)-1])
+	for _, f := range funs[:len(funs)-1] {
+		info.Middlewares = append(info.Middlewares, util.GetFunctionName(f))
+	}
+	return
+}
+
+// name 路由函数名称
+func (node *routerNode) name() string {
+	if node.rf != nil {
+		return util.GetFunctionName(node.rf)
+	}
+	if len(node.funs) > 0 {
+		return util.GetFunctionName(node.funs[len(node.funs)-1])
+	}
+	return ""
+}
+
+// noRouterInfo 未注册路由处理信息
+func noRouterInfo(noCache []MiddlewareFunc) (routes []RouteInfo) {
+	if noCache == nil {
+		return
+	}
+	info := routeInfo(nil, noCache)
+	info.NoRouter = true
+	return append(routes, info)
+}
+
+// warnRegister 注册路由失败告警. 生成代码注册路由时忽略错误,不同服务之间的消息ID/URI冲突在启动时输出.
+func warnRegister(uri interface{}, node *routerNode, err error) error {
+	if err != nil {
+		zaplog.GetFrameLogger().New("process.Router").Error("register router failed",
+			zap.Any("uri", uri),
+			zap.String("handler", node.name()),
+			zap.Error(err),
+		)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package process
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const pkg = "github.com/walleframe/walle/process."
+
+func routeAuth(ctx Context)  {}
+func routeLogin(ctx Context) {}
+func routeChat(ctx Context)  {}
+func routeKick(ctx Context)  {}
+func routeNo(ctx Context)    {}
+
+func TestRouter_Routes(t *testing.T) {
+	routers := map[string]func() Router{
+		"mix":  func() Router { return &MixRouter{} },
+		"trie": func() Router { return NewTrieRouter() },
+		"cow":  func() Router { return NewCOWRouter() },
+	}
+	for name, newRouter := range routers {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter()
+			r.Use(routeAuth)
+			assert.Nil(t, r.Register("/login", routeLogin))
+			assert.Nil(t, r.Register(uint32(100), routeLogin))
+			assert.Nil(t, r.Group("/admin").Register("/kick", routeKick, routeAuth))
+			assert.Nil(t, r.NoRouter(routeNo))
+			if name != "mix" {
+				assert.Nil(t, r.Register("/room/:id/chat", routeChat))
+			}
+
+			routes := ListRoutes(r)
+			assert.Equal(t, RouteInfo{MsgID: 100, Handler: pkg + "routeLogin", Middlewares: []string{pkg + "routeAuth"}}, routes[0])
+			assert.Equal(t, RouteInfo{URI: "/admin/kick", Handler: pkg + "routeKick", Middlewares: []string{pkg + "routeAuth", pkg + "routeAuth"}}, routes[1])
+			assert.Equal(t, RouteInfo{URI: "/login", Handler: pkg + "routeLogin", Middlewares: []string{pkg + "routeAuth"}}, routes[2])
+			if name != "mix" {
+				assert.Equal(t, RouteInfo{URI: "/room/:id/chat", Handler: pkg + "routeChat", Middlewares: []string{pkg + "routeAuth"}}, routes[3])
+			}
+			assert.Equal(t, RouteInfo{NoRouter: true, Handler: pkg + "routeNo", Middlewares: []string{pkg + "routeAuth"}}, routes[len(routes)-1])
+
+			// 冲突时错误信息包含已注册的处理函数
+			err := r.Register(uint32(100), routeChat)
+			assert.True(t, errors.Is(err, ErrRouterKeyRepated))
+			assert.Contains(t, err.Error(), pkg+"routeLogin")
+
+			buf := &bytes.Buffer{}
+			assert.Nil(t, DumpRoutes(buf, r))
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Equal(t, len(routes)+1, len(lines))
+			assert.Contains(t, lines[1], pkg+"routeLogin")
+		})
+	}
+}
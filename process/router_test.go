@@ -145,3 +145,30 @@ func TestMixRouter_Methods(t *testing.T) {
 		})
 	}
 }
+
+func TestMixRouter_CompactCodec(t *testing.T) {
+	r := &MixRouter{}
+	var called string
+	r.Register(uint32(1), func(ctx Context) { called = "id" })
+	r.Register("kk", func(ctx Context) { called = "uri" })
+
+	// 同时携带消息ID和URI, 消息ID优先匹配, 未注册的ID使用URI匹配
+	for id, want := range map[uint32]string{1: "id", 2: "uri"} {
+		src := packet.NewPacket()
+		src.SetCmd(packet.CmdRequest)
+		src.SetMsgID(id)
+		src.SetURI("kk")
+		data, err := packet.CompactCodec.Marshal(src)
+		assert.Nil(t, err, "marshal")
+		pkg := packet.NewPacket()
+		assert.Nil(t, packet.CompactCodec.Unmarshal(data, pkg), "unmarshal")
+		assert.EqualValues(t, id, pkg.MsgID())
+		assert.Equal(t, "kk", pkg.URI())
+
+		handlers, err := r.GetHandlers(pkg)
+		assert.Nil(t, err, "get router handlers")
+		ctx := &WrapContext{SrcContext: context.Background(), Handlers: handlers}
+		ctx.Next(ctx)
+		assert.Equal(t, want, called, id)
+	}
+}
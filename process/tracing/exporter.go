@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+// Exporter 导出结束的span. 可能在多个协程中同时调用.
+type Exporter interface {
+	Export(span *Span)
+}
+
+type exporterHolder struct {
+	Exporter
+}
+
+var exporter atomic.Value // exporterHolder
+
+// SetExporter 设置全局导出接口. nil 关闭链路追踪.
+func SetExporter(exp Exporter) {
+	exporter.Store(exporterHolder{exp})
+}
+
+// GetExporter 获取全局导出接口
+func GetExporter() Exporter {
+	holder, _ := exporter.Load().(exporterHolder)
+	return holder.Exporter
+}
+
+// Enabled 是否开启链路追踪(已设置 Exporter)
+func Enabled() bool {
+	return GetExporter() != nil
+}
+
+// MemoryExporter 内存导出,用于测试
+type MemoryExporter struct {
+	mux   sync.Mutex
+	spans []*Span
+}
+
+var _ Exporter = (*MemoryExporter)(nil)
+
+// NewMemoryExporter new memory exporter
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// Export 保存span
+func (exp *MemoryExporter) Export(span *Span) {
+	exp.mux.Lock()
+	defer exp.mux.Unlock()
+	exp.spans = append(exp.spans, span)
+}
+
+// Spans 已导出的span(按照结束顺序)
+func (exp *MemoryExporter) Spans() []*Span {
+	exp.mux.Lock()
+	defer exp.mux.Unlock()
+	return append([]*Span(nil), exp.spans...)
+}
+
+// Reset 清空
+func (exp *MemoryExporter) Reset() {
+	exp.mux.Lock()
+	defer exp.mux.Unlock()
+	exp.spans = nil
+}
+
+// JSONExporter 每个span写入一行json(JSON Lines)
+type JSONExporter struct {
+	mux sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+var _ Exporter = (*JSONExporter)(nil)
+
+// NewJSONExporter 写入w
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+// NewFileExporter 追加写入文件
+func NewFileExporter(filename string) (*JSONExporter, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONExporter(f), nil
+}
+
+// Export 写入一行json
+func (exp *JSONExporter) Export(span *Span) {
+	exp.mux.Lock()
+	defer exp.mux.Unlock()
+	if err := exp.enc.Encode(span); err != nil {
+		zaplog.GetFrameLogger().New("tracing.JSONExporter").Error("export span failed",
+			zap.Error(err), zap.Stringer("trace_id", span.TraceID), zap.String("name", span.Name))
+	}
+}
+
+// Close 关闭写入接口(实现了 io.Closer 时)
+func (exp *JSONExporter) Close() error {
+	exp.mux.Lock()
+	defer exp.mux.Unlock()
+	if c, ok := exp.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"strconv"
+
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	"go.uber.org/zap"
+)
+
+// Middleware 记录处理函数span. 使用请求metadata中的traceparent作为父节点,
+// span保存在Context中,处理函数内的rpc请求自动传递链路信息.
+func Middleware() process.MiddlewareFunc {
+	return func(ctx process.Context) {
+		if !Enabled() {
+			ctx.Next(ctx)
+			return
+		}
+		var parent SpanContext
+		if md, err := ctx.GetReqeustMD(); err == nil {
+			parent, _ = Extract(md)
+		}
+		span := NewSpan(parent, "", SpanKindServer)
+		if pkg, ok := ctx.GetRequestPacket().(*packet.Packet); ok {
+			span.Name = spanName(pkg.URI(), pkg.MsgID())
+			span.SetAttr("uri", pkg.URI())
+			span.SetAttr("msgid", pkg.MsgID())
+			span.SetAttr("cmd", pkg.Cmd())
+		}
+		var rspErr error
+		process.AddRespondHook(ctx, func(body interface{}, md metadata.MD) metadata.MD {
+			if e, ok := body.(error); ok {
+				rspErr = e
+			}
+			return md
+		})
+		ctx.WithValue(spanCtxKey{}, span)
+		ctx.WithLogFields(zap.Stringer("trace_id", span.TraceID))
+		ctx.Next(ctx)
+		span.Finish(rspErr)
+	}
+}
+
+// spanName 消息ID路由使用 "msgid:{id}"
+func spanName(uri string, msgID uint32) string {
+	if msgID > 0 {
+		return "msgid:" + strconv.FormatUint(uint64(msgID), 10)
+	}
+	return uri
+}
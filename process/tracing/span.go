@@ -0,0 +1,255 @@
+// Package tracing 分布式链路追踪. 使用 W3C traceparent 格式在请求metadata中传递链路信息,
+// 记录处理函数( Middleware )和rpc请求( rpc.RPCProcess )的span,通过 Exporter 导出.
+//
+// 未设置 Exporter 时不记录span,也不传递链路信息.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/walleframe/walle/process/metadata"
+)
+
+// TraceparentKey 链路信息的metadata key. 格式: 00-{trace-id}-{parent-id}-{flags}
+const TraceparentKey = "traceparent"
+
+// ErrInvalidTraceparent traceparent格式错误
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID 链路ID
+type TraceID [16]byte
+
+// IsValid 是否有效(非全0)
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText json导出为16进制字符串
+func (id TraceID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// SpanID span ID
+type SpanID [8]byte
+
+// IsValid 是否有效(非全0)
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText json导出为16进制字符串
+func (id SpanID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// SpanContext 需要跨服务传递的链路信息
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid 是否有效
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent 转换为traceparent格式
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent 解析traceparent
+func ParseTraceparent(s string) (sc SpanContext, err error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	// 版本00只能有4部分,更高版本兼容解析前4部分
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	var flags [1]byte
+	if _, err = hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	if _, err = hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	if _, err = hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	sc.Sampled = flags[0]&0x01 != 0
+	return sc, nil
+}
+
+// Inject 请求metadata添加链路信息. 返回新的metadata,不修改md. span为nil时返回md.
+func Inject(md metadata.MD, span *Span) metadata.MD {
+	if span == nil {
+		return md
+	}
+	return metadata.Join(md, metadata.Pairs(TraceparentKey, span.SpanContext().Traceparent()))
+}
+
+// Extract 获取请求metadata中的链路信息
+func Extract(md metadata.MD) (sc SpanContext, ok bool) {
+	v, ok := md.GetFirstString(TraceparentKey)
+	if !ok {
+		return
+	}
+	sc, err := ParseTraceparent(v)
+	return sc, err == nil
+}
+
+// SpanKind span类型
+type SpanKind string
+
+const (
+	// SpanKindServer 处理请求
+	SpanKindServer SpanKind = "server"
+	// SpanKindClient rpc请求
+	SpanKindClient SpanKind = "client"
+	// SpanKindProducer 发送通知
+	SpanKindProducer SpanKind = "producer"
+)
+
+// Span 一次处理或者请求. 结束( End )之后导出,导出之后不能修改.
+type Span struct {
+	TraceID  TraceID                `json:"trace_id"`
+	SpanID   SpanID                 `json:"span_id"`
+	ParentID SpanID                 `json:"parent_id"`
+	Name     string                 `json:"name"`
+	Kind     SpanKind               `json:"kind"`
+	Start    time.Time              `json:"start"`
+	End      time.Time              `json:"end"`
+	Attrs    map[string]interface{} `json:"attrs,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Sampled  bool                   `json:"-"`
+
+	mux   sync.Mutex
+	ended bool
+}
+
+// NewSpan 新建span. parent无效时开始新的链路.
+func NewSpan(parent SpanContext, name string, kind SpanKind) *Span {
+	span := &Span{
+		Name:    name,
+		Kind:    kind,
+		Start:   time.Now(),
+		Sampled: true,
+	}
+	if parent.IsValid() {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+		span.Sampled = parent.Sampled
+	} else {
+		for !span.TraceID.IsValid() {
+			rand.Read(span.TraceID[:])
+		}
+	}
+	for !span.SpanID.IsValid() {
+		rand.Read(span.SpanID[:])
+	}
+	return span
+}
+
+// StartSpan 新建span,使用ctx中的span作为父节点. 未设置 Exporter 时返回nil.
+func StartSpan(ctx context.Context, name string, kind SpanKind) *Span {
+	if !Enabled() {
+		return nil
+	}
+	var parent SpanContext
+	if span := FromContext(ctx); span != nil {
+		parent = span.SpanContext()
+	}
+	return NewSpan(parent, name, kind)
+}
+
+// SpanContext 需要传递的链路信息
+func (span *Span) SpanContext() SpanContext {
+	if span == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: span.TraceID, SpanID: span.SpanID, Sampled: span.Sampled}
+}
+
+// SetAttr 设置属性
+func (span *Span) SetAttr(key string, value interface{}) {
+	if span == nil {
+		return
+	}
+	span.mux.Lock()
+	defer span.mux.Unlock()
+	if span.ended {
+		return
+	}
+	if span.Attrs == nil {
+		span.Attrs = make(map[string]interface{})
+	}
+	span.Attrs[key] = value
+}
+
+// Finish 结束span并导出. 重复调用无效.
+func (span *Span) Finish(err error) {
+	if span == nil {
+		return
+	}
+	span.mux.Lock()
+	if span.ended {
+		span.mux.Unlock()
+		return
+	}
+	span.ended = true
+	span.End = time.Now()
+	if err != nil {
+		span.Error = err.Error()
+	}
+	span.mux.Unlock()
+	if !span.Sampled {
+		return
+	}
+	if exp := GetExporter(); exp != nil {
+		exp.Export(span)
+	}
+}
+
+// Duration 耗时
+func (span *Span) Duration() time.Duration {
+	return span.End.Sub(span.Start)
+}
+
+type spanCtxKey struct{}
+
+// FromContext 获取ctx中的span
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanCtxKey{}).(*Span)
+	return span
+}
+
+// ContextWithSpan 返回保存span的ctx
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanCtxKey{}, span)
+}
@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/process/message"
+	"github.com/walleframe/walle/process/metadata"
+	"github.com/walleframe/walle/process/packet"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+func TestTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Nil(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xx",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(v)
+		assert.ErrorIs(t, err, ErrInvalidTraceparent, v)
+	}
+
+	span := NewSpan(sc, "child", SpanKindClient)
+	md := Inject(metadata.Pairs("k", "v"), span)
+	got, ok := Extract(md)
+	assert.True(t, ok)
+	assert.Equal(t, span.SpanContext(), got)
+	assert.Equal(t, sc.TraceID, span.TraceID)
+	assert.Equal(t, sc.SpanID, span.ParentID)
+}
+
+func TestJSONExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exp := NewJSONExporter(buf)
+	SetExporter(exp)
+	defer SetExporter(nil)
+
+	span := NewSpan(SpanContext{}, "a", SpanKindServer)
+	span.SetAttr("k", "v")
+	span.Finish(errors.New("failed"))
+	span.Finish(nil)
+	NewSpan(span.SpanContext(), "b", SpanKindClient).Finish(nil)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	v := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(lines[0], &v))
+	assert.Equal(t, span.TraceID.String(), v["trace_id"])
+	assert.Equal(t, "a", v["name"])
+	assert.Equal(t, "failed", v["error"])
+	assert.Equal(t, map[string]interface{}{"k": "v"}, v["attrs"])
+	assert.Nil(t, exp.Close())
+}
+
+func TestMiddleware(t *testing.T) {
+	exp := NewMemoryExporter()
+	SetExporter(exp)
+	defer SetExporter(nil)
+
+	parent := NewSpan(SpanContext{}, "caller", SpanKindClient)
+	var inner *Span
+	r := process.NewTrieRouter()
+	r.Use(Middleware())
+	r.Register("kk", func(ctx process.Context) {
+		inner = FromContext(ctx)
+		ctx.Respond(ctx, errors.New("failed"), nil)
+	})
+	p := process.NewProcess(
+		process.NewInnerOptions(
+			process.WithInnerOptionOutput(&bytes.Buffer{}),
+			process.WithInnerOptionRouter(r),
+		),
+		process.NewProcessOptions(
+			process.WithLogger(zaplog.NewLogger(zap.NewNop())),
+			process.WithMsgCodec(message.JSONCodec),
+		),
+	)
+	rq := packet.NewTestPacket(packet.CmdRequest, []byte("{}"), Inject(nil, parent))
+	rq.SetURI("kk")
+	data, err := packet.GetCodec().Marshal(rq)
+	assert.Nil(t, err)
+	assert.Nil(t, p.OnRead(data))
+
+	spans := exp.Spans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, inner, spans[0])
+		assert.Equal(t, "kk", spans[0].Name)
+		assert.Equal(t, SpanKindServer, spans[0].Kind)
+		assert.Equal(t, parent.TraceID, spans[0].TraceID)
+		assert.Equal(t, parent.SpanID, spans[0].ParentID)
+		assert.Equal(t, "failed", spans[0].Error)
+	}
+
+	// 未开启时不记录
+	SetExporter(nil)
+	exp.Reset()
+	inner = nil
+	assert.Nil(t, p.OnRead(data))
+	assert.Nil(t, inner)
+	assert.Empty(t, exp.Spans())
+}
@@ -0,0 +1,307 @@
+package process
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
+)
+
+// TrieRouter 前缀树路由. 支持路径参数和通配符:
+//
+//	/room/:roomId/chat 匹配 /room/10/chat, 参数 roomId=10
+//	/admin/*           匹配 /admin/a/b, 参数 *=a/b
+//	/static/*path      匹配 /static/a/b, 参数 path=a/b
+//
+// 匹配优先级: 静态路径 > 参数 > 通配符. 消息ID路由和 MixRouter 一样优先匹配.
+// 路由必须先注册,再使用.(内部无锁)
+type TrieRouter struct {
+	middlewares []MiddlewareFunc
+	handlersID  map[uint32]*routerNode
+	// 不包含参数的路径直接查找
+	static  map[string]*routerNode
+	root    *trieNode
+	noCache []MiddlewareFunc
+}
+
+var _ ParamsRouter = (*TrieRouter)(nil)
+
+// NewTrieRouter new trie router
+func NewTrieRouter() *TrieRouter {
+	return &TrieRouter{}
+}
+
+type trieNode struct {
+	// 静态子节点
+	children map[string]*trieNode
+	// 参数子节点 :name
+	param     *trieNode
+	paramName string
+	// 通配符子节点 *name
+	wildcard     *trieNode
+	wildcardName string
+	// 完整路由
+	route *routerNode
+}
+
+// Use 设置全局中间件，在Use之后注册的接口都会使用此中间件
+func (r *TrieRouter) Use(m ...MiddlewareFunc) {
+	if len(m) < 1 || r == nil {
+		return
+	}
+	r.middlewares = append(r.middlewares, m...)
+}
+
+// NoRouter 未设置路由请求
+func (r *TrieRouter) NoRouter(rf RouterFunc, mid ...MiddlewareFunc) (err error) {
+	if r.noCache != nil {
+		err = fmt.Errorf("norouter %w", ErrRouterKeyRepated)
+		return
+	}
+	r.noCache = append(r.noCache, r.middlewares...)
+	r.noCache = append(r.noCache, mid...)
+	r.noCache = append(r.noCache, rf)
+	return
+}
+
+// Register 注册路由. uri 支持 string(路径模式), uint32/int(消息ID)
+func (r *TrieRouter) Register(uri interface{}, rf RouterFunc, m ...MiddlewareFunc) (err error) {
+	return r.registerNode(uri, r.newNode(nil, rf, m...))
+}
+
+// Group 新建路由分组
+func (r *TrieRouter) Group(prefix string, m ...MiddlewareFunc) Router {
+	return newRouterGroup(r, nil, prefix, m...)
+}
+
+func (r *TrieRouter) newNode(group *routerGroup, rf RouterFunc, m ...MiddlewareFunc) *routerNode {
+	return newRouterNode(r.middlewares, group, rf, m...)
+}
+
+func (r *TrieRouter) update(f func()) error {
+	f()
+	return nil
+}
+
+func (r *TrieRouter) registerNode(uri interface{}, node *routerNode) (err error) {
+	switch v := uri.(type) {
+	case string:
+		err = r.regPath(v, node)
+	case uint32:
+		err = r.regRequestID(v, node)
+	case int:
+		err = r.regRequestID(uint32(v), node)
+	default:
+		// 未支持的类型
+		err = errcode.WrapError(errcode.ErrUnexpectedCode,
+			fmt.Errorf("register uri type[%s] not suppert", reflect.TypeOf(uri).Name()),
+		)
+	}
+	if err == nil && node.stat == nil {
+		node.stat = newRouteStat(uri)
+	}
+	if err == nil && node.group != nil {
+		node.group.nodes = append(node.group.nodes, node)
+	}
+	return warnRegister(uri, node, err)
+}
+
+// regRequestID 使用请求id注册处理方法
+func (r *TrieRouter) regRequestID(id uint32, node *routerNode) (err error) {
+	if r.handlersID == nil {
+		r.handlersID = make(map[uint32]*routerNode)
+	}
+	if exist, ok := r.handlersID[id]; ok {
+		return fmt.Errorf("RQID %d registered by %s, %w", id, exist.name(), ErrRouterKeyRepated)
+	}
+	r.handlersID[id] = node
+	return
+}
+
+// regPath 注册路径
+func (r *TrieRouter) regPath(path string, node *routerNode) (err error) {
+	if !strings.ContainsAny(path, ":*") {
+		if r.static == nil {
+			r.static = make(map[string]*routerNode)
+		}
+		if exist, ok := r.static[path]; ok {
+			return fmt.Errorf("path %s registered by %s, %w", path, exist.name(), ErrRouterKeyRepated)
+		}
+		r.static[path] = node
+		return
+	}
+	if r.root == nil {
+		r.root = &trieNode{}
+	}
+	cur := r.root
+	segs := splitPath(path)
+	for k, seg := range segs {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			name := seg[1:]
+			if name == "" {
+				return fmt.Errorf("path %s empty param name, %w", path, ErrRouterInvalidPath)
+			}
+			if cur.param == nil {
+				cur.param = &trieNode{}
+				cur.paramName = name
+			} else if cur.paramName != name {
+				return fmt.Errorf("path %s param %s conflict with :%s, %w", path, seg, cur.paramName, ErrRouterInvalidPath)
+			}
+			cur = cur.param
+		case strings.HasPrefix(seg, "*"):
+			if k != len(segs)-1 {
+				return fmt.Errorf("path %s wildcard must be last segment, %w", path, ErrRouterInvalidPath)
+			}
+			name := seg[1:]
+			if name == "" {
+				name = "*"
+			}
+			if cur.wildcard != nil {
+				return fmt.Errorf("path %s registered by %s, %w", path, cur.wildcard.route.name(), ErrRouterKeyRepated)
+			}
+			cur.wildcard = &trieNode{route: node}
+			cur.wildcardName = name
+			return
+		default:
+			if cur.children == nil {
+				cur.children = make(map[string]*trieNode)
+			}
+			next, ok := cur.children[seg]
+			if !ok {
+				next = &trieNode{}
+				cur.children[seg] = next
+			}
+			cur = next
+		}
+	}
+	if cur.route != nil {
+		return fmt.Errorf("path %s registered by %s, %w", path, cur.route.name(), ErrRouterKeyRepated)
+	}
+	cur.route = node
+	return
+}
+
+// GetHandlers 获取请求对应处理函数
+func (r *TrieRouter) GetHandlers(in interface{}) (handlers []RouterFunc, err error) {
+	handlers, _, err = r.GetHandlersParams(in)
+	return
+}
+
+// GetHandlersParams 获取请求对应处理函数和路径参数
+func (r *TrieRouter) GetHandlersParams(in interface{}) (handlers []RouterFunc, params Params, err error) {
+	handlers, params, _, err = r.getHandlersStat(in)
+	return
+}
+
+func (r *TrieRouter) getHandlersStat(in interface{}) (handlers []RouterFunc, params Params, stat *routeStat, err error) {
+	p, ok := in.(*packet.Packet)
+	if !ok || p == nil {
+		err = ErrNotFoundRequest
+		return
+	}
+	if r.handlersID != nil && p.MsgID() > 0 {
+		if node, ok := r.handlersID[p.MsgID()]; ok {
+			return node.funs, nil, node.stat, nil
+		}
+	}
+	uri := p.URI()
+	if node, ok := r.static[uri]; ok {
+		return node.funs, nil, node.stat, nil
+	}
+	if r.root != nil {
+		if node := r.root.match(uri, &params); node != nil {
+			return node.funs, params, node.stat, nil
+		}
+	}
+	if r.noCache == nil {
+		err = fmt.Errorf("uri %s %w", uri, ErrRouterNotSupport)
+		return
+	}
+	return r.noCache, nil, noRouterStat, nil
+}
+
+// Routes 已注册路由. 参数路径返回注册时的模式
+func (r *TrieRouter) Routes() (routes []RouteInfo) {
+	for id, node := range r.handlersID {
+		routes = append(routes, routeInfo(id, node.funs))
+	}
+	for path, node := range r.static {
+		routes = append(routes, routeInfo(path, node.funs))
+	}
+	if r.root != nil {
+		r.root.walk("", func(path string, node *routerNode) {
+			routes = append(routes, routeInfo(path, node.funs))
+		})
+	}
+	return append(routes, noRouterInfo(r.noCache)...)
+}
+
+// walk 遍历路由节点
+func (n *trieNode) walk(prefix string, f func(path string, node *routerNode)) {
+	if n.route != nil {
+		f(prefix, n.route)
+	}
+	for seg, next := range n.children {
+		next.walk(prefix+"/"+seg, f)
+	}
+	if n.param != nil {
+		n.param.walk(prefix+"/:"+n.paramName, f)
+	}
+	if n.wildcard != nil {
+		name := n.wildcardName
+		if name == "*" {
+			name = ""
+		}
+		f(prefix+"/*"+name, n.wildcard.route)
+	}
+}
+
+// match 匹配路径,优先级: 静态路径 > 参数 > 通配符
+func (n *trieNode) match(path string, params *Params) *routerNode {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		if n.route != nil {
+			return n.route
+		}
+		if n.wildcard != nil {
+			*params = append(*params, Param{Key: n.wildcardName})
+			return n.wildcard.route
+		}
+		return nil
+	}
+	seg, rest := path, ""
+	if idx := strings.IndexByte(path, '/'); idx >= 0 {
+		seg, rest = path[:idx], path[idx:]
+	}
+	if next, ok := n.children[seg]; ok {
+		if node := next.match(rest, params); node != nil {
+			return node
+		}
+	}
+	if n.param != nil {
+		count := len(*params)
+		*params = append(*params, Param{Key: n.paramName, Value: seg})
+		if node := n.param.match(rest, params); node != nil {
+			return node
+		}
+		*params = (*params)[:count]
+	}
+	if n.wildcard != nil {
+		*params = append(*params, Param{Key: n.wildcardName, Value: path})
+		return n.wildcard.route
+	}
+	return nil
+}
+
+// splitPath 拆分路径
+func splitPath(path string) (segs []string) {
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	return
+}
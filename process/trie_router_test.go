@@ -0,0 +1,93 @@
+package process
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/packet"
+)
+
+func TestTrieRouter_Match(t *testing.T) {
+	r := NewTrieRouter()
+	var called string
+	reg := func(uri interface{}) {
+		name, _ := uri.(string)
+		if name == "" {
+			name = "id"
+		}
+		assert.Nil(t, r.Register(uri, func(ctx Context) { called = name }), "register %v", uri)
+	}
+	reg("/room/list")
+	reg("/room/:roomId/chat")
+	reg("/room/:roomId/user/:uid")
+	reg("/admin/*")
+	reg("/static/*path")
+	reg(uint32(1))
+	assert.Nil(t, r.NoRouter(func(ctx Context) { called = "norouter" }))
+
+	datas := []struct {
+		uri    string
+		msgID  uint32
+		route  string
+		params Params
+	}{
+		{uri: "/room/list", route: "/room/list"},
+		{uri: "/room/10/chat", route: "/room/:roomId/chat", params: Params{{"roomId", "10"}}},
+		{uri: "/room/10/user/7", route: "/room/:roomId/user/:uid", params: Params{{"roomId", "10"}, {"uid", "7"}}},
+		{uri: "/admin/a/b", route: "/admin/*", params: Params{{"*", "a/b"}}},
+		{uri: "/static/js/app.js", route: "/static/*path", params: Params{{"path", "js/app.js"}}},
+		{uri: "/room/10/chat", msgID: 1, route: "id"},
+		{uri: "/room/10", route: "norouter"},
+		{uri: "/unknown", route: "norouter"},
+	}
+	for _, v := range datas {
+		t.Run(v.uri, func(t *testing.T) {
+			pkg := packet.NewPacket()
+			pkg.SetURI(v.uri)
+			pkg.SetMsgID(v.msgID)
+			handlers, params, err := r.GetHandlersParams(pkg)
+			assert.Nil(t, err)
+			if assert.Len(t, handlers, 1) {
+				handlers[0](nil)
+			}
+			assert.Equal(t, v.route, called)
+			assert.Equal(t, v.params, params)
+		})
+	}
+}
+
+func TestTrieRouter_Register(t *testing.T) {
+	r := NewTrieRouter()
+	f := func(ctx Context) {}
+	assert.Nil(t, r.Register("/room/:roomId/chat", f))
+	assert.True(t, errors.Is(r.Register("/room/:roomId/chat", f), ErrRouterKeyRepated))
+	assert.True(t, errors.Is(r.Register("/room/:id/info", f), ErrRouterInvalidPath))
+	assert.True(t, errors.Is(r.Register("/admin/*/x", f), ErrRouterInvalidPath))
+	assert.True(t, errors.Is(r.Register("/room/:", f), ErrRouterInvalidPath))
+
+	pkg := packet.NewPacket()
+	pkg.SetURI("/room/1/info")
+	_, err := r.GetHandlers(pkg)
+	assert.True(t, errors.Is(err, ErrRouterNotSupport))
+
+	// 非packet请求
+	_, err = r.GetHandlers("/room/1/chat")
+	assert.True(t, errors.Is(err, ErrNotFoundRequest))
+	_, err = r.GetHandlers((*packet.Packet)(nil))
+	assert.True(t, errors.Is(err, ErrNotFoundRequest))
+}
+
+func TestProcess_RouterParams(t *testing.T) {
+	r := NewTrieRouter()
+	var roomID string
+	assert.Nil(t, r.Register("/room/:roomId/chat", func(ctx Context) {
+		roomID = ctx.Param("roomId")
+	}))
+	p := NewProcess(NewInnerOptions(WithInnerOptionRouter(r)), NewProcessOptions())
+	pkg := packet.NewPacket()
+	pkg.SetCmd(packet.CmdRequest)
+	pkg.SetURI("/room/42/chat")
+	assert.Nil(t, p.innerPacket(pkg))
+	assert.Equal(t, "42", roomID)
+}
@@ -0,0 +1,50 @@
+package process
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/packet"
+)
+
+var validators sync.Map // reflect.Type => func(msg interface{}) error
+
+// RegisterValidator 注册消息检查函数,优先于消息的 Validate 方法.
+// 用于不能修改的消息类型(生成代码,第三方消息).
+func RegisterValidator[T any](f func(msg *T) error) {
+	validators.Store(reflect.TypeOf((*T)(nil)), func(msg interface{}) error {
+		return f(msg.(*T))
+	})
+}
+
+// ValidateMessage 检查消息参数,使用注册的检查函数或者消息的 Validate() error 方法.
+// 作为 WithValidator 选项开启 Bind 参数检查.
+// 检查失败返回 *errcode.InvalidArgument (检查函数返回其他错误码时不转换).
+func ValidateMessage(msg interface{}) (err error) {
+	if f, ok := validators.Load(reflect.TypeOf(msg)); ok {
+		err = f.(func(msg interface{}) error)(msg)
+	} else if v, ok := msg.(interface{ Validate() error }); ok {
+		err = v.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+	var code interface{ Codes() uint32 }
+	if errors.As(err, &code) {
+		return err
+	}
+	return errcode.NewInvalidArgument(err.Error())
+}
+
+// validate Bind 参数检查. 只检查请求和通知消息,不检查rpc响应.
+func (ctx *WrapContext) validate(body interface{}) error {
+	if ctx.Opts.Validator == nil || body == nil {
+		return nil
+	}
+	if p, ok := ctx.InPkg.(*packet.Packet); ok && p.Cmd() == packet.CmdResponse {
+		return nil
+	}
+	return ctx.Opts.Validator(body)
+}
@@ -0,0 +1,84 @@
+package process
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walleframe/walle/process/errcode"
+	"github.com/walleframe/walle/process/message"
+	"github.com/walleframe/walle/process/packet"
+)
+
+type testValidRq struct {
+	Name string `json:"name"`
+}
+
+func (rq *testValidRq) Validate() error {
+	if rq.Name == "" {
+		return errcode.InvalidField("name", "required")
+	}
+	return nil
+}
+
+type testRegisteredRq struct {
+	Age int `json:"age"`
+}
+
+func TestValidateMessage(t *testing.T) {
+	RegisterValidator(func(rq *testRegisteredRq) error {
+		if rq.Age < 0 {
+			return errors.New("age must be positive")
+		}
+		return nil
+	})
+	assert.Nil(t, ValidateMessage(&testValidRq{Name: "a"}))
+	assert.Nil(t, ValidateMessage(&testAddRq{}))
+	var invalid *errcode.InvalidArgument
+	err := ValidateMessage(&testValidRq{})
+	if assert.True(t, errors.As(err, &invalid)) {
+		assert.Equal(t, []errcode.FieldViolation{{Field: "name", Desc: "required"}}, invalid.Violations)
+	}
+	// 普通错误转换为参数错误
+	err = ValidateMessage(&testRegisteredRq{Age: -1})
+	if assert.True(t, errors.As(err, &invalid)) {
+		assert.Equal(t, "invalid argument: age must be positive", err.Error())
+	}
+}
+
+func TestBind_Validate(t *testing.T) {
+	for _, enable := range []bool{false, true} {
+		r := NewTrieRouter()
+		calls := 0
+		Handle(r, "/hello", func(ctx Context, rq *testValidRq) (*testAddRs, error) {
+			calls++
+			return nil, nil
+		})
+		out := &bytes.Buffer{}
+		opts := NewProcessOptions(WithMsgCodec(message.JSONCodec))
+		if enable {
+			opts.ApplyOption(WithValidator(ValidateMessage))
+		}
+		p := NewProcess(NewInnerOptions(WithInnerOptionRouter(r), WithInnerOptionOutput(out)), opts)
+		pkg := packet.NewTestPacket(packet.CmdRequest, []byte(`{}`), nil)
+		pkg.SetURI("/hello")
+		assert.Nil(t, p.innerPacket(pkg))
+
+		rsp := packet.NewPacket()
+		assert.Nil(t, p.Opts.PacketCodec.Unmarshal(out.Bytes(), rsp))
+		err := p.Opts.PacketWraper.PayloadUnmarshal(rsp, p.Opts.MsgCodec, nil)
+		if !enable {
+			assert.Nil(t, err)
+			assert.Equal(t, 1, calls)
+			continue
+		}
+		assert.Equal(t, 0, calls)
+		assert.True(t, errcode.Is(err, errcode.ErrorCodeInvalidArgument))
+		assert.Equal(t, "12-invalid argument [name: required]", err.Error())
+		var invalid *errcode.InvalidArgument
+		if assert.True(t, errors.As(err, &invalid)) {
+			assert.Equal(t, []errcode.FieldViolation{{Field: "name", Desc: "required"}}, invalid.Violations)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/walleframe/walle/app"
+	"github.com/walleframe/walle/app/bootstrap"
+	"github.com/walleframe/walle/process"
+	"github.com/walleframe/walle/services/configcentra"
+	"github.com/walleframe/walle/util/metrics"
+	"github.com/walleframe/walle/zaplog"
+	"go.uber.org/zap"
+)
+
+// AdminService 内置管理http服务. 提供 /healthz /readyz 等本地探测接口和 /metrics 监控指标.
+type AdminService struct {
+	app.NoopService
+	addr     string
+	mux      *http.ServeMux
+	svr      *http.Server
+	reporter app.HealthReporter
+	once     sync.Once
+}
+
+func NewService(addr string) *AdminService {
+	svc := &AdminService{
+		addr: addr,
+		mux:  http.NewServeMux(),
+	}
+	svc.mux.HandleFunc("/healthz", svc.healthz)
+	svc.mux.HandleFunc("/readyz", svc.readyz)
+	svc.mux.HandleFunc("/debug/routes", svc.routes)
+	svc.mux.Handle("/metrics", metrics.Handler(metrics.Default()))
+	return svc
+}
+
+func (svc *AdminService) Name() string {
+	return "admin"
+}
+
+// Handle 注册管理接口
+func (svc *AdminService) Handle(pattern string, handler http.Handler) {
+	svc.mux.Handle(pattern, handler)
+}
+
+// HandleFunc 注册管理接口
+func (svc *AdminService) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	svc.mux.HandleFunc(pattern, handler)
+}
+
+// Init 监听并开始服务. 其他服务初始化过程中也可以探测状态.
+func (svc *AdminService) Init(s app.Stoper) (err error) {
+	svc.reporter, _ = s.(app.HealthReporter)
+	ln, err := net.Listen("tcp", svc.addr)
+	if err != nil {
+		return err
+	}
+	svc.svr = &http.Server{Handler: svc.mux}
+	go func() {
+		err := svc.svr.Serve(ln)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			zaplog.GetFrameLogger().New("admin.Serve").Error("admin http serve failed", zap.String("addr", svc.addr), zap.Error(err))
+		}
+	}()
+	return
+}
+
+func (svc *AdminService) Stop() {
+	svc.StopContext(context.Background())
+}
+
+func (svc *AdminService) StopContext(ctx context.Context) {
+	svc.once.Do(func() {
+		if svc.svr != nil {
+			svc.svr.Shutdown(ctx)
+		}
+	})
+}
+
+func (svc *AdminService) healthz(w http.ResponseWriter, r *http.Request) {
+	if svc.reporter == nil {
+		writeStatus(w, app.HealthStatus{OK: true})
+		return
+	}
+	writeStatus(w, svc.reporter.Health(r.Context()))
+}
+
+func (svc *AdminService) readyz(w http.ResponseWriter, r *http.Request) {
+	if svc.reporter == nil {
+		writeStatus(w, app.HealthStatus{
+			Services: []app.ServiceHealth{{Name: "application", Error: ErrNoReporter.Error()}},
+		})
+		return
+	}
+	writeStatus(w, svc.reporter.Ready(r.Context()))
+}
+
+// routes 输出默认路由表, ?format=json 返回json格式
+func (svc *AdminService) routes(w http.ResponseWriter, r *http.Request) {
+	router := process.GetRouter()
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(process.ListRoutes(router))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	process.DumpRoutes(w, router)
+}
+
+func writeStatus(w http.ResponseWriter, status app.HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	if !status.OK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+var ErrNoReporter = errors.New("application not support health report")
+
+// 管理服务
+var (
+	gAdminService *AdminService
+	registerOnce  sync.Once
+)
+
+func init() {
+	gAdminService = NewService(":9090")
+	configcentra.String(&gAdminService.addr, "admin.addr", ":9090", "admin http listen address")
+}
+
+// Register 注册管理服务(默认不注册). 监听地址使用配置项 admin.addr
+func Register() {
+	registerOnce.Do(func() {
+		bootstrap.RegisterServiceByPriority(100, gAdminService, "config-manager")
+	})
+}
+
+// Handle 注册管理接口
+func Handle(pattern string, handler http.Handler) {
+	gAdminService.Handle(pattern, handler)
+}
+
+// HandleFunc 注册管理接口
+func HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	gAdminService.HandleFunc(pattern, handler)
+}
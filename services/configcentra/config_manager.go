@@ -1,6 +1,7 @@
 package configcentra
 
 import (
+	"context"
 	"errors"
 	"sync/atomic"
 
@@ -17,6 +18,7 @@ type cacheValue struct {
 type ConfigCentraService struct {
 	app.NoopService
 	start   atomic.Bool
+	loaded  atomic.Bool
 	values  []cacheValue
 	updates []ConfigUpdateNotify
 	flags   []FlagNotify
@@ -59,10 +61,40 @@ func (svc *ConfigCentraService) Init(s app.Stoper) (err error) {
 	if err != nil {
 		return err
 	}
+	svc.loaded.Store(true)
 
 	return
 }
 
+// Ready config loaded
+func (svc *ConfigCentraService) Ready(ctx context.Context) error {
+	if !svc.loaded.Load() {
+		return ErrConfigNotLoaded
+	}
+	if checker, ok := ConfigCentraBackend.(app.ReadyChecker); ok {
+		return checker.Ready(ctx)
+	}
+	return nil
+}
+
+// Health config centra backend health
+func (svc *ConfigCentraService) Health(ctx context.Context) error {
+	if checker, ok := ConfigCentraBackend.(app.HealthChecker); ok {
+		return checker.Health(ctx)
+	}
+	return nil
+}
+
+// Reload config centra backend reload
+func (svc *ConfigCentraService) Reload(ctx context.Context) error {
+	if reloader, ok := ConfigCentraBackend.(app.Reloader); ok {
+		return reloader.Reload(ctx)
+	}
+	return nil
+}
+
+var ErrConfigNotLoaded = errors.New("config not loaded")
+
 func (svc *ConfigCentraService) Start(s app.Stoper) error {
 	return ConfigCentraBackend.Start(s)
 }
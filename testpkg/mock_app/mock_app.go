@@ -5,6 +5,7 @@
 package mock_app
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -99,3 +100,110 @@ func (mr *MockServiceMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockService)(nil).Stop))
 }
+
+// MockContextStoper is a mock of ContextStoper interface.
+type MockContextStoper struct {
+	ctrl     *gomock.Controller
+	recorder *MockContextStoperMockRecorder
+}
+
+// MockContextStoperMockRecorder is the mock recorder for MockContextStoper.
+type MockContextStoperMockRecorder struct {
+	mock *MockContextStoper
+}
+
+// NewMockContextStoper creates a new mock instance.
+func NewMockContextStoper(ctrl *gomock.Controller) *MockContextStoper {
+	mock := &MockContextStoper{ctrl: ctrl}
+	mock.recorder = &MockContextStoperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContextStoper) EXPECT() *MockContextStoperMockRecorder {
+	return m.recorder
+}
+
+// StopContext mocks base method.
+func (m *MockContextStoper) StopContext(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopContext", ctx)
+}
+
+// StopContext indicates an expected call of StopContext.
+func (mr *MockContextStoperMockRecorder) StopContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContext", reflect.TypeOf((*MockContextStoper)(nil).StopContext), ctx)
+}
+
+// MockContextFinisher is a mock of ContextFinisher interface.
+type MockContextFinisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockContextFinisherMockRecorder
+}
+
+// MockContextFinisherMockRecorder is the mock recorder for MockContextFinisher.
+type MockContextFinisherMockRecorder struct {
+	mock *MockContextFinisher
+}
+
+// NewMockContextFinisher creates a new mock instance.
+func NewMockContextFinisher(ctrl *gomock.Controller) *MockContextFinisher {
+	mock := &MockContextFinisher{ctrl: ctrl}
+	mock.recorder = &MockContextFinisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContextFinisher) EXPECT() *MockContextFinisherMockRecorder {
+	return m.recorder
+}
+
+// FinishContext mocks base method.
+func (m *MockContextFinisher) FinishContext(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FinishContext", ctx)
+}
+
+// FinishContext indicates an expected call of FinishContext.
+func (mr *MockContextFinisherMockRecorder) FinishContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishContext", reflect.TypeOf((*MockContextFinisher)(nil).FinishContext), ctx)
+}
+
+// MockDepender is a mock of Depender interface.
+type MockDepender struct {
+	ctrl     *gomock.Controller
+	recorder *MockDependerMockRecorder
+}
+
+// MockDependerMockRecorder is the mock recorder for MockDepender.
+type MockDependerMockRecorder struct {
+	mock *MockDepender
+}
+
+// NewMockDepender creates a new mock instance.
+func NewMockDepender(ctrl *gomock.Controller) *MockDepender {
+	mock := &MockDepender{ctrl: ctrl}
+	mock.recorder = &MockDependerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDepender) EXPECT() *MockDependerMockRecorder {
+	return m.recorder
+}
+
+// Depends mocks base method.
+func (m *MockDepender) Depends() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depends")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Depends indicates an expected call of Depends.
+func (mr *MockDependerMockRecorder) Depends() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depends", reflect.TypeOf((*MockDepender)(nil).Depends))
+}
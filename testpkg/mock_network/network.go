@@ -83,6 +83,59 @@ func (mr *MockCallerMockRecorder) Notify(ctx, uri, rq, opts interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockCaller)(nil).Notify), ctx, uri, rq, opts)
 }
 
+// MockStreamCaller is a mock of StreamCaller interface.
+type MockStreamCaller struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamCallerMockRecorder
+}
+
+// MockStreamCallerMockRecorder is the mock recorder for MockStreamCaller.
+type MockStreamCallerMockRecorder struct {
+	mock *MockStreamCaller
+}
+
+// NewMockStreamCaller creates a new mock instance.
+func NewMockStreamCaller(ctrl *gomock.Controller) *MockStreamCaller {
+	mock := &MockStreamCaller{ctrl: ctrl}
+	mock.recorder = &MockStreamCallerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamCaller) EXPECT() *MockStreamCallerMockRecorder {
+	return m.recorder
+}
+
+// AcceptStream mocks base method.
+func (m *MockStreamCaller) AcceptStream(ctx process.Context) (rpc.Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptStream", ctx)
+	ret0, _ := ret[0].(rpc.Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptStream indicates an expected call of AcceptStream.
+func (mr *MockStreamCallerMockRecorder) AcceptStream(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptStream", reflect.TypeOf((*MockStreamCaller)(nil).AcceptStream), ctx)
+}
+
+// NewStream mocks base method.
+func (m *MockStreamCaller) NewStream(ctx context.Context, uri interface{}, opts *rpc.StreamOptions) (rpc.Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewStream", ctx, uri, opts)
+	ret0, _ := ret[0].(rpc.Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewStream indicates an expected call of NewStream.
+func (mr *MockStreamCallerMockRecorder) NewStream(ctx, uri, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewStream", reflect.TypeOf((*MockStreamCaller)(nil).NewStream), ctx, uri, opts)
+}
+
 // MockCallerResponser is a mock of CallerResponser interface.
 type MockCallerResponser struct {
 	ctrl     *gomock.Controller
@@ -692,6 +745,34 @@ func (mr *MockSessionContextMockRecorder) Notify(ctx, uri, rq, opts interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockSessionContext)(nil).Notify), ctx, uri, rq, opts)
 }
 
+// Param mocks base method.
+func (m *MockSessionContext) Param(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Param", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Param indicates an expected call of Param.
+func (mr *MockSessionContextMockRecorder) Param(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Param", reflect.TypeOf((*MockSessionContext)(nil).Param), name)
+}
+
+// Params mocks base method.
+func (m *MockSessionContext) Params() process.Params {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Params")
+	ret0, _ := ret[0].(process.Params)
+	return ret0
+}
+
+// Params indicates an expected call of Params.
+func (mr *MockSessionContextMockRecorder) Params() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Params", reflect.TypeOf((*MockSessionContext)(nil).Params))
+}
+
 // Respond mocks base method.
 func (m *MockSessionContext) Respond(arg0 context.Context, body interface{}, md metadata.MD) error {
 	m.ctrl.T.Helper()
@@ -720,6 +801,18 @@ func (mr *MockSessionContextMockRecorder) SessionValue(key interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionValue", reflect.TypeOf((*MockSessionContext)(nil).SessionValue), key)
 }
 
+// SetParams mocks base method.
+func (m *MockSessionContext) SetParams(params process.Params) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetParams", params)
+}
+
+// SetParams indicates an expected call of SetParams.
+func (mr *MockSessionContextMockRecorder) SetParams(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetParams", reflect.TypeOf((*MockSessionContext)(nil).SetParams), params)
+}
+
 // Value mocks base method.
 func (m *MockSessionContext) Value(key interface{}) interface{} {
 	m.ctrl.T.Helper()
@@ -1159,6 +1252,34 @@ func (mr *MockClientContextMockRecorder) Notify(ctx, uri, rq, opts interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockClientContext)(nil).Notify), ctx, uri, rq, opts)
 }
 
+// Param mocks base method.
+func (m *MockClientContext) Param(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Param", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Param indicates an expected call of Param.
+func (mr *MockClientContextMockRecorder) Param(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Param", reflect.TypeOf((*MockClientContext)(nil).Param), name)
+}
+
+// Params mocks base method.
+func (m *MockClientContext) Params() process.Params {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Params")
+	ret0, _ := ret[0].(process.Params)
+	return ret0
+}
+
+// Params indicates an expected call of Params.
+func (mr *MockClientContextMockRecorder) Params() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Params", reflect.TypeOf((*MockClientContext)(nil).Params))
+}
+
 // Respond mocks base method.
 func (m *MockClientContext) Respond(arg0 context.Context, body interface{}, md metadata.MD) error {
 	m.ctrl.T.Helper()
@@ -1173,6 +1294,18 @@ func (mr *MockClientContextMockRecorder) Respond(arg0, body, md interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Respond", reflect.TypeOf((*MockClientContext)(nil).Respond), arg0, body, md)
 }
 
+// SetParams mocks base method.
+func (m *MockClientContext) SetParams(params process.Params) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetParams", params)
+}
+
+// SetParams indicates an expected call of SetParams.
+func (mr *MockClientContextMockRecorder) SetParams(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetParams", reflect.TypeOf((*MockClientContext)(nil).SetParams), params)
+}
+
 // Value mocks base method.
 func (m *MockClientContext) Value(key interface{}) interface{} {
 	m.ctrl.T.Helper()
@@ -177,6 +177,34 @@ func (mr *MockContextMockRecorder) Next(nctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockContext)(nil).Next), nctx)
 }
 
+// Param mocks base method.
+func (m *MockContext) Param(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Param", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Param indicates an expected call of Param.
+func (mr *MockContextMockRecorder) Param(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Param", reflect.TypeOf((*MockContext)(nil).Param), name)
+}
+
+// Params mocks base method.
+func (m *MockContext) Params() process.Params {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Params")
+	ret0, _ := ret[0].(process.Params)
+	return ret0
+}
+
+// Params indicates an expected call of Params.
+func (mr *MockContextMockRecorder) Params() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Params", reflect.TypeOf((*MockContext)(nil).Params))
+}
+
 // Respond mocks base method.
 func (m *MockContext) Respond(arg0 context.Context, body interface{}, md metadata.MD) error {
 	m.ctrl.T.Helper()
@@ -191,6 +219,18 @@ func (mr *MockContextMockRecorder) Respond(arg0, body, md interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Respond", reflect.TypeOf((*MockContext)(nil).Respond), arg0, body, md)
 }
 
+// SetParams mocks base method.
+func (m *MockContext) SetParams(params process.Params) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetParams", params)
+}
+
+// SetParams indicates an expected call of SetParams.
+func (mr *MockContextMockRecorder) SetParams(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetParams", reflect.TypeOf((*MockContext)(nil).SetParams), params)
+}
+
 // Value mocks base method.
 func (m *MockContext) Value(key interface{}) interface{} {
 	m.ctrl.T.Helper()
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: executor.go
+
+// Package mock_process is a generated GoMock package.
+package mock_process
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	process "github.com/walleframe/walle/process"
+)
+
+// MockExecutor is a mock of Executor interface.
+type MockExecutor struct {
+	ctrl     *gomock.Controller
+	recorder *MockExecutorMockRecorder
+}
+
+// MockExecutorMockRecorder is the mock recorder for MockExecutor.
+type MockExecutorMockRecorder struct {
+	mock *MockExecutor
+}
+
+// NewMockExecutor creates a new mock instance.
+func NewMockExecutor(ctrl *gomock.Controller) *MockExecutor {
+	mock := &MockExecutor{ctrl: ctrl}
+	mock.recorder = &MockExecutorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExecutor) EXPECT() *MockExecutorMockRecorder {
+	return m.recorder
+}
+
+// Dispatch mocks base method.
+func (m *MockExecutor) Dispatch(inner *process.InnerOptions, pkg interface{}, next process.PacketDispatcherFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dispatch", inner, pkg, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Dispatch indicates an expected call of Dispatch.
+func (mr *MockExecutorMockRecorder) Dispatch(inner, pkg, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dispatch", reflect.TypeOf((*MockExecutor)(nil).Dispatch), inner, pkg, next)
+}
@@ -49,6 +49,25 @@ func (mr *MockRouterMockRecorder) GetHandlers(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHandlers", reflect.TypeOf((*MockRouter)(nil).GetHandlers), p)
 }
 
+// Group mocks base method.
+func (m_2 *MockRouter) Group(prefix string, m ...process.MiddlewareFunc) process.Router {
+	m_2.ctrl.T.Helper()
+	varargs := []interface{}{prefix}
+	for _, a := range m {
+		varargs = append(varargs, a)
+	}
+	ret := m_2.ctrl.Call(m_2, "Group", varargs...)
+	ret0, _ := ret[0].(process.Router)
+	return ret0
+}
+
+// Group indicates an expected call of Group.
+func (mr *MockRouterMockRecorder) Group(prefix interface{}, m ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{prefix}, m...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Group", reflect.TypeOf((*MockRouter)(nil).Group), varargs...)
+}
+
 // NoRouter mocks base method.
 func (m *MockRouter) NoRouter(rf process.RouterFunc, mid ...process.MiddlewareFunc) error {
 	m.ctrl.T.Helper()
@@ -102,3 +121,130 @@ func (mr *MockRouterMockRecorder) Use(m ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockRouter)(nil).Use), m...)
 }
+
+// MockParamsRouter is a mock of ParamsRouter interface.
+type MockParamsRouter struct {
+	ctrl     *gomock.Controller
+	recorder *MockParamsRouterMockRecorder
+}
+
+// MockParamsRouterMockRecorder is the mock recorder for MockParamsRouter.
+type MockParamsRouterMockRecorder struct {
+	mock *MockParamsRouter
+}
+
+// NewMockParamsRouter creates a new mock instance.
+func NewMockParamsRouter(ctrl *gomock.Controller) *MockParamsRouter {
+	mock := &MockParamsRouter{ctrl: ctrl}
+	mock.recorder = &MockParamsRouterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockParamsRouter) EXPECT() *MockParamsRouterMockRecorder {
+	return m.recorder
+}
+
+// GetHandlers mocks base method.
+func (m *MockParamsRouter) GetHandlers(p interface{}) ([]process.RouterFunc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHandlers", p)
+	ret0, _ := ret[0].([]process.RouterFunc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHandlers indicates an expected call of GetHandlers.
+func (mr *MockParamsRouterMockRecorder) GetHandlers(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHandlers", reflect.TypeOf((*MockParamsRouter)(nil).GetHandlers), p)
+}
+
+// GetHandlersParams mocks base method.
+func (m *MockParamsRouter) GetHandlersParams(p interface{}) ([]process.RouterFunc, process.Params, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHandlersParams", p)
+	ret0, _ := ret[0].([]process.RouterFunc)
+	ret1, _ := ret[1].(process.Params)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetHandlersParams indicates an expected call of GetHandlersParams.
+func (mr *MockParamsRouterMockRecorder) GetHandlersParams(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHandlersParams", reflect.TypeOf((*MockParamsRouter)(nil).GetHandlersParams), p)
+}
+
+// Group mocks base method.
+func (m_2 *MockParamsRouter) Group(prefix string, m ...process.MiddlewareFunc) process.Router {
+	m_2.ctrl.T.Helper()
+	varargs := []interface{}{prefix}
+	for _, a := range m {
+		varargs = append(varargs, a)
+	}
+	ret := m_2.ctrl.Call(m_2, "Group", varargs...)
+	ret0, _ := ret[0].(process.Router)
+	return ret0
+}
+
+// Group indicates an expected call of Group.
+func (mr *MockParamsRouterMockRecorder) Group(prefix interface{}, m ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{prefix}, m...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Group", reflect.TypeOf((*MockParamsRouter)(nil).Group), varargs...)
+}
+
+// NoRouter mocks base method.
+func (m *MockParamsRouter) NoRouter(rf process.RouterFunc, mid ...process.MiddlewareFunc) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{rf}
+	for _, a := range mid {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "NoRouter", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NoRouter indicates an expected call of NoRouter.
+func (mr *MockParamsRouterMockRecorder) NoRouter(rf interface{}, mid ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{rf}, mid...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NoRouter", reflect.TypeOf((*MockParamsRouter)(nil).NoRouter), varargs...)
+}
+
+// Register mocks base method.
+func (m_2 *MockParamsRouter) Register(uri interface{}, rf process.RouterFunc, m ...process.MiddlewareFunc) error {
+	m_2.ctrl.T.Helper()
+	varargs := []interface{}{uri, rf}
+	for _, a := range m {
+		varargs = append(varargs, a)
+	}
+	ret := m_2.ctrl.Call(m_2, "Register", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockParamsRouterMockRecorder) Register(uri, rf interface{}, m ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{uri, rf}, m...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockParamsRouter)(nil).Register), varargs...)
+}
+
+// Use mocks base method.
+func (m_2 *MockParamsRouter) Use(m ...process.MiddlewareFunc) {
+	m_2.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range m {
+		varargs = append(varargs, a)
+	}
+	m_2.ctrl.Call(m_2, "Use", varargs...)
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockParamsRouterMockRecorder) Use(m ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockParamsRouter)(nil).Use), m...)
+}
@@ -0,0 +1,392 @@
+// Package metrics 监控指标(计数器,仪表盘,直方图),支持标签,输出 Prometheus 文本格式.
+//
+// 指标注册到 Registry ,包级别函数使用默认注册表( Default ). 同一注册表中指标名称不能重复.
+//
+//	var requests = metrics.NewCounter("app_requests_total", "requests count", "uri")
+//	requests.With("/login").Inc()
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefBuckets 默认直方图区间(秒)
+var DefBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Counter 计数器,只能增加
+type Counter struct {
+	v atomicFloat
+}
+
+// Inc 加1
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add 增加v,v必须大于等于0
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.v.add(v)
+}
+
+// Value 当前值
+func (c *Counter) Value() float64 {
+	return c.v.load()
+}
+
+// Gauge 仪表盘,可以任意设置
+type Gauge struct {
+	v atomicFloat
+	f atomic.Value // func() float64
+}
+
+// Set 设置值
+func (g *Gauge) Set(v float64) {
+	g.v.store(v)
+}
+
+// Add 增加v(可以为负数)
+func (g *Gauge) Add(v float64) {
+	g.v.add(v)
+}
+
+// Inc 加1
+func (g *Gauge) Inc() {
+	g.v.add(1)
+}
+
+// Dec 减1
+func (g *Gauge) Dec() {
+	g.v.add(-1)
+}
+
+// SetFunc 采集时调用f获取值(队列长度等不方便实时更新的值)
+func (g *Gauge) SetFunc(f func() float64) {
+	g.f.Store(f)
+}
+
+// Value 当前值
+func (g *Gauge) Value() float64 {
+	if f, ok := g.f.Load().(func() float64); ok && f != nil {
+		return f()
+	}
+	return g.v.load()
+}
+
+// Histogram 直方图
+type Histogram struct {
+	upper  []float64
+	counts []uint64
+	count  uint64
+	sum    atomicFloat
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		upper:  buckets,
+		counts: make([]uint64, len(buckets)),
+	}
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(v float64) {
+	idx := sort.SearchFloat64s(h.upper, v)
+	if idx < len(h.counts) {
+		atomic.AddUint64(&h.counts[idx], 1)
+	}
+	h.sum.add(v)
+	atomic.AddUint64(&h.count, 1)
+}
+
+// ObserveDuration 记录耗时(秒)
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// Count 观测次数
+func (h *Histogram) Count() uint64 {
+	return atomic.LoadUint64(&h.count)
+}
+
+// Sum 观测值总和
+func (h *Histogram) Sum() float64 {
+	return h.sum.load()
+}
+
+// metricType 指标类型
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// vec 带标签的指标集合
+type vec struct {
+	name   string
+	help   string
+	typ    metricType
+	labels []string
+	newFn  func() interface{}
+	mux    sync.RWMutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	metric interface{}
+}
+
+func (v *vec) with(values []string) interface{} {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expected %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mux.RLock()
+	s, ok := v.series[key]
+	v.mux.RUnlock()
+	if ok {
+		return s.metric
+	}
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	if s, ok = v.series[key]; ok {
+		return s.metric
+	}
+	s = &series{
+		values: append([]string(nil), values...),
+		metric: v.newFn(),
+	}
+	v.series[key] = s
+	return s.metric
+}
+
+func (v *vec) delete(values []string) bool {
+	key := strings.Join(values, "\xff")
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	_, ok := v.series[key]
+	delete(v.series, key)
+	return ok
+}
+
+// sorted 按照标签值排序,输出稳定
+func (v *vec) sorted() []*series {
+	v.mux.RLock()
+	list := make([]*series, 0, len(v.series))
+	for _, s := range v.series {
+		list = append(list, s)
+	}
+	v.mux.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].values, list[j].values
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return list
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	vec
+}
+
+// With 获取标签值对应的计数器
+func (v *CounterVec) With(values ...string) *Counter {
+	return v.with(values).(*Counter)
+}
+
+// Delete 删除标签值对应的计数器
+func (v *CounterVec) Delete(values ...string) bool {
+	return v.delete(values)
+}
+
+// GaugeVec 带标签的仪表盘
+type GaugeVec struct {
+	vec
+}
+
+// With 获取标签值对应的仪表盘
+func (v *GaugeVec) With(values ...string) *Gauge {
+	return v.with(values).(*Gauge)
+}
+
+// Delete 删除标签值对应的仪表盘
+func (v *GaugeVec) Delete(values ...string) bool {
+	return v.delete(values)
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	vec
+	buckets []float64
+}
+
+// With 获取标签值对应的直方图
+func (v *HistogramVec) With(values ...string) *Histogram {
+	return v.with(values).(*Histogram)
+}
+
+// Delete 删除标签值对应的直方图
+func (v *HistogramVec) Delete(values ...string) bool {
+	return v.delete(values)
+}
+
+// Registry 指标注册表
+type Registry struct {
+	mux     sync.RWMutex
+	metrics map[string]*vec
+}
+
+// NewRegistry new metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]*vec),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default 默认注册表
+func Default() *Registry {
+	return defaultRegistry
+}
+
+func (r *Registry) register(v *vec) {
+	if !validName(v.name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", v.name))
+	}
+	for _, l := range v.labels {
+		if !validName(l) || strings.HasPrefix(l, "__") || l == "le" {
+			panic(fmt.Sprintf("metrics: %s invalid label name %q", v.name, l))
+		}
+	}
+	v.series = make(map[string]*series)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if _, ok := r.metrics[v.name]; ok {
+		panic(fmt.Sprintf("metrics: %s already registered", v.name))
+	}
+	r.metrics[v.name] = v
+}
+
+// NewCounter 注册计数器
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{vec{name: name, help: help, typ: typeCounter, labels: labels,
+		newFn: func() interface{} { return &Counter{} },
+	}}
+	r.register(&v.vec)
+	return v
+}
+
+// NewGauge 注册仪表盘
+func (r *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	v := &GaugeVec{vec{name: name, help: help, typ: typeGauge, labels: labels,
+		newFn: func() interface{} { return &Gauge{} },
+	}}
+	r.register(&v.vec)
+	return v
+}
+
+// NewHistogram 注册直方图. buckets 为空时使用 DefBuckets
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	if math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+	v := &HistogramVec{buckets: buckets}
+	v.vec = vec{name: name, help: help, typ: typeHistogram, labels: labels,
+		newFn: func() interface{} { return newHistogram(buckets) },
+	}
+	r.register(&v.vec)
+	return v
+}
+
+// Unregister 删除指标
+func (r *Registry) Unregister(name string) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	_, ok := r.metrics[name]
+	delete(r.metrics, name)
+	return ok
+}
+
+// NewCounter 默认注册表注册计数器
+func NewCounter(name, help string, labels ...string) *CounterVec {
+	return defaultRegistry.NewCounter(name, help, labels...)
+}
+
+// NewGauge 默认注册表注册仪表盘
+func NewGauge(name, help string, labels ...string) *GaugeVec {
+	return defaultRegistry.NewGauge(name, help, labels...)
+}
+
+// NewHistogram 默认注册表注册直方图
+func NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return defaultRegistry.NewHistogram(name, help, buckets, labels...)
+}
+
+// Handler 输出 Prometheus 文本格式的http接口
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// validName 指标和标签名称 [a-zA-Z_:][a-zA-Z0-9_:]*
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for k, c := range name {
+		switch {
+		case c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && k > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// atomicFloat 原子操作float64
+type atomicFloat struct {
+	bits uint64
+}
+
+func (f *atomicFloat) load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.bits))
+}
+
+func (f *atomicFloat) store(v float64) {
+	atomic.StoreUint64(&f.bits, math.Float64bits(v))
+}
+
+func (f *atomicFloat) add(v float64) {
+	for {
+		old := atomic.LoadUint64(&f.bits)
+		nv := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(&f.bits, old, nv) {
+			return
+		}
+	}
+}
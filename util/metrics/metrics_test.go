@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("test_requests_total", "requests count", "uri", "cmd")
+	requests.With("/login", "request").Inc()
+	requests.With("/login", "request").Add(2)
+	requests.With("/chat", "notify").Inc()
+	sessions := r.NewGauge("test_sessions", "sessions\nnow")
+	sessions.With().Inc()
+	sessions.With().Inc()
+	sessions.With().Dec()
+	queue := r.NewGauge("test_queue", "", "addr")
+	queue.With(`:80"x`).SetFunc(func() float64 { return 7 })
+	latency := r.NewHistogram("test_latency_seconds", "latency", []float64{0.5, 0.1, 1}, "uri")
+	latency.With("/login").Observe(0.05)
+	latency.With("/login").ObserveDuration(700 * time.Millisecond)
+	latency.With("/login").Observe(3)
+	r.NewCounter("test_empty_total", "no series")
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, r.WriteText(buf))
+	assert.Equal(t, strings.Join([]string{
+		`# HELP test_latency_seconds latency`,
+		`# TYPE test_latency_seconds histogram`,
+		`test_latency_seconds_bucket{uri="/login",le="0.1"} 1`,
+		`test_latency_seconds_bucket{uri="/login",le="0.5"} 1`,
+		`test_latency_seconds_bucket{uri="/login",le="1"} 2`,
+		`test_latency_seconds_bucket{uri="/login",le="+Inf"} 3`,
+		`test_latency_seconds_sum{uri="/login"} 3.75`,
+		`test_latency_seconds_count{uri="/login"} 3`,
+		`# TYPE test_queue gauge`,
+		`test_queue{addr=":80\"x"} 7`,
+		`# HELP test_requests_total requests count`,
+		`# TYPE test_requests_total counter`,
+		`test_requests_total{uri="/chat",cmd="notify"} 1`,
+		`test_requests_total{uri="/login",cmd="request"} 3`,
+		`# HELP test_sessions sessions\nnow`,
+		`# TYPE test_sessions gauge`,
+		`test_sessions 1`,
+		``,
+	}, "\n"), buf.String())
+
+	// 删除
+	assert.True(t, queue.Delete(`:80"x`))
+	assert.True(t, r.Unregister("test_latency_seconds"))
+	rec := httptest.NewRecorder()
+	Handler(r).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "version=0.0.4")
+	assert.NotContains(t, rec.Body.String(), "test_queue")
+	assert.NotContains(t, rec.Body.String(), "test_latency_seconds")
+}
+
+func TestRegisterPanic(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_total", "")
+	assert.Panics(t, func() { r.NewGauge("test_total", "") }, "duplicate name")
+	assert.Panics(t, func() { r.NewGauge("0test", "") }, "invalid name")
+	assert.Panics(t, func() { r.NewHistogram("test_h", "", nil, "le") }, "reserved label")
+	assert.Panics(t, func() { r.NewCounter("test_c", "", "a").With("x", "y") }, "label values count")
+	assert.Panics(t, func() { r.NewCounter("test_d", "").With().Add(-1) }, "counter decrease")
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// WriteText 按照 Prometheus 文本格式(0.0.4)输出所有指标
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mux.RLock()
+	list := make([]*vec, 0, len(r.metrics))
+	for _, v := range r.metrics {
+		list = append(list, v)
+	}
+	r.mux.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, v := range list {
+		v.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+func (v *vec) writeText(w *bufio.Writer) {
+	all := v.sorted()
+	if len(all) == 0 {
+		return
+	}
+	if v.help != "" {
+		w.WriteString("# HELP " + v.name + " " + escapeHelp(v.help) + "\n")
+	}
+	w.WriteString("# TYPE " + v.name + " " + string(v.typ) + "\n")
+	for _, s := range all {
+		switch m := s.metric.(type) {
+		case *Counter:
+			writeSample(w, v.name, v.labels, s.values, "", "", m.Value())
+		case *Gauge:
+			writeSample(w, v.name, v.labels, s.values, "", "", m.Value())
+		case *Histogram:
+			var cumulative uint64
+			for k, upper := range m.upper {
+				cumulative += atomic.LoadUint64(&m.counts[k])
+				writeSample(w, v.name+"_bucket", v.labels, s.values, "le", formatFloat(upper), float64(cumulative))
+			}
+			count := m.Count()
+			writeSample(w, v.name+"_bucket", v.labels, s.values, "le", "+Inf", float64(count))
+			writeSample(w, v.name+"_sum", v.labels, s.values, "", "", m.Sum())
+			writeSample(w, v.name+"_count", v.labels, s.values, "", "", float64(count))
+		}
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for k, l := range labels {
+			if k > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(l + `="` + escapeLabel(values[k]) + `"`)
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraName + `="` + extraValue + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
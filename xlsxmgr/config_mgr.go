@@ -2,8 +2,10 @@ package xlsxmgr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/walleframe/walle/app"
@@ -20,7 +22,8 @@ type XlsxConfig struct {
 	ctx    context.Context
 	cancel func()
 
-	data sync.Map // 配置数据
+	data   sync.Map    // 配置数据
+	loaded atomic.Bool // 配置是否加载完成
 }
 
 func NewXlsxConfigManager() *XlsxConfig {
@@ -43,9 +46,24 @@ func (mgr *XlsxConfig) UpdateConfig(s app.Stoper) error {
 	mgr.mutex.Lock() // 保证同一时间. 只运行一次
 	defer mgr.mutex.Unlock()
 
-	return mgr.plugin.Start(mgr.ctx, mgr, s)
+	err := mgr.plugin.Start(mgr.ctx, mgr, s)
+	if err != nil {
+		return err
+	}
+	mgr.loaded.Store(true)
+	return nil
 }
 
+// Ready 配置是否已经加载完成
+func (mgr *XlsxConfig) Ready(ctx context.Context) error {
+	if !mgr.loaded.Load() {
+		return ErrNotLoaded
+	}
+	return nil
+}
+
+var ErrNotLoaded = errors.New("xlsx config not loaded")
+
 // Stop 停止监听变动
 func (mgr *XlsxConfig) Stop() {
 	mgr.cancel()
@@ -0,0 +1,33 @@
+package zaplog
+
+import (
+	"github.com/walleframe/walle/app"
+	"go.uber.org/zap"
+)
+
+// NewLifecycleObserver 使用日志对象输出服务生命周期日志
+func NewLifecycleObserver(logger *Logger) app.LifecycleObserver {
+	return app.LifecycleObserverFunc(func(evt *app.LifecycleEvent) {
+		log := logger.New("app.lifecycle")
+		switch {
+		case !evt.Done:
+			log.Debug("service wait",
+				zap.String("service", evt.Service),
+				zap.String("phase", string(evt.Phase)),
+			)
+		case evt.Err != nil:
+			log.Error("service failed",
+				zap.String("service", evt.Service),
+				zap.String("phase", string(evt.Phase)),
+				zap.Duration("duration", evt.Duration),
+				zap.Error(evt.Err),
+			)
+		default:
+			log.Info("service finish",
+				zap.String("service", evt.Service),
+				zap.String("phase", string(evt.Phase)),
+				zap.Duration("duration", evt.Duration),
+			)
+		}
+	})
+}